@@ -0,0 +1,337 @@
+// Command linearize inspects, diffs and patches Protobuf messages using a descriptor set.
+//
+// Usage:
+//
+//	linearize -I descriptors.binpb -type package.Message <command> [arguments]
+//
+// Commands:
+//
+//	flatten msg.binpb          print every populated leaf of a message as path = value
+//	diff a.binpb b.binpb       write the patch that turns a into b
+//	apply patch.bin base.binpb apply a patch to base and write the resulting message
+//	render patch.bin           print a patch as a unified diff
+//
+// Messages may be binary or protojson encoded. Files ending in .json are read as protojson and
+// other files as binary, unless -format names the format of every message file.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fgrzl/linearize"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "linearize:", err)
+		os.Exit(1)
+	}
+}
+
+// config holds the parsed command line
+type config struct {
+	descriptorSet string
+	messageType   string
+	output        string
+	format        string
+	json          bool
+	color         bool
+	maxValue      int
+}
+
+// formats lists the values of -format.
+var formats = map[string]bool{"": true, "binary": true, "json": true}
+
+// commands lists the operands of each command.
+var commands = map[string][]string{
+	"flatten": {"message"},
+	"diff":    {"previous", "latest"},
+	"apply":   {"patch", "base"},
+	"render":  {"patch"},
+}
+
+// run executes the command line and writes results to stdout unless -o is given. The -o file is
+// only written once the command has succeeded, so a failing command leaves it untouched.
+func run(args []string, stdout io.Writer) error {
+	var cfg config
+	flags := flag.NewFlagSet("linearize", flag.ContinueOnError)
+	flags.StringVar(&cfg.descriptorSet, "I", "", "descriptor set file produced by protoc --descriptor_set_out")
+	flags.StringVar(&cfg.descriptorSet, "descriptor_set_in", "", "alias for -I")
+	flags.StringVar(&cfg.messageType, "type", "", "fully qualified message type name")
+	flags.StringVar(&cfg.output, "o", "", "write output to a file instead of stdout")
+	flags.StringVar(&cfg.format, "format", "", "format of message files, binary or json; by default .json files are protojson")
+	flags.BoolVar(&cfg.json, "json", false, "write messages as protojson")
+	flags.BoolVar(&cfg.color, "color", false, "color rendered diffs")
+	flags.IntVar(&cfg.maxValue, "max_value", 0, "truncate rendered values after this many characters")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: linearize -I descriptors.binpb -type package.Message <flatten|diff|apply|render> [files]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing command")
+	}
+	if cfg.descriptorSet == "" || cfg.messageType == "" {
+		return errors.New("both -I and -type are required")
+	}
+	if !formats[cfg.format] {
+		return fmt.Errorf("unknown -format %q", cfg.format)
+	}
+
+	command, operands := flags.Arg(0), flags.Args()[1:]
+	names, known := commands[command]
+	if !known {
+		return fmt.Errorf("unknown command %q", command)
+	}
+	if len(operands) != len(names) {
+		return fmt.Errorf("usage: %s <%s>", command, strings.Join(names, "> <"))
+	}
+
+	files, md, err := loadDescriptor(cfg.descriptorSet, cfg.messageType)
+	if err != nil {
+		return err
+	}
+	types := dynamicpb.NewTypes(files)
+
+	if cfg.output == "" {
+		return execute(stdout, &cfg, command, operands, md, types)
+	}
+	var out bytes.Buffer
+	if err := execute(&out, &cfg, command, operands, md, types); err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.output, out.Bytes(), 0o666)
+}
+
+// execute runs a command whose operands have been checked and writes its result to out.
+func execute(out io.Writer, cfg *config, command string, operands []string, md protoreflect.MessageDescriptor, types *dynamicpb.Types) error {
+	switch command {
+	case "flatten":
+		obj, err := readLinearized(operands[0], cfg.format, md, types)
+		if err != nil {
+			return err
+		}
+		return flatten(out, md, obj)
+
+	case "diff":
+		previous, err := readLinearized(operands[0], cfg.format, md, types)
+		if err != nil {
+			return err
+		}
+		latest, err := readLinearized(operands[1], cfg.format, md, types)
+		if err != nil {
+			return err
+		}
+		before, after, mask, err := linearize.Diff(previous, latest, linearize.WithDescriptor(md))
+		if err != nil {
+			return err
+		}
+		data, err := (&linearize.Patch{Mask: mask, Before: before, After: after}).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+
+	case "apply":
+		patch, err := readPatch(operands[0])
+		if err != nil {
+			return err
+		}
+		current, err := readMessage(operands[1], cfg.format, md, types)
+		if err != nil {
+			return err
+		}
+		if err := linearize.MergeInto(current, patch.Mask, patch.After); err != nil {
+			return fmt.Errorf("failed to apply patch: %w", err)
+		}
+		return writeMessage(out, current, cfg.json, types)
+
+	case "render":
+		patch, err := readPatch(operands[0])
+		if err != nil {
+			return err
+		}
+		renderer := linearize.Renderer{Color: cfg.color, MaxValueLength: cfg.maxValue}
+		return renderer.Render(out, md, patch.Before, patch.After, patch.Mask)
+	}
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// loadDescriptor reads a FileDescriptorSet and resolves the named message.
+func loadDescriptor(path, messageType string) (*protoregistry.Files, protoreflect.MessageDescriptor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse descriptor set %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load descriptor set %s: %w", path, err)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(messageType))
+	if err != nil {
+		return nil, nil, fmt.Errorf("message type %s: %w", messageType, err)
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a message type", messageType)
+	}
+	return files, md, nil
+}
+
+// readLinearized reads a message like readMessage and linearizes it.
+func readLinearized(path, format string, md protoreflect.MessageDescriptor, types *dynamicpb.Types) (linearize.LinearizedObject, error) {
+	msg, err := readMessage(path, format, md, types)
+	if err != nil {
+		return nil, err
+	}
+	return linearize.Linearize(msg)
+}
+
+// readMessage reads a binary or protojson message. Without a format, files ending in .json are protojson.
+func readMessage(path, format string, md protoreflect.MessageDescriptor, types *dynamicpb.Types) (*dynamicpb.Message, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if format == "json" || (format == "" && strings.EqualFold(filepath.Ext(path), ".json")) {
+		err = protojson.UnmarshalOptions{Resolver: types}.Unmarshal(data, msg)
+	} else {
+		err = proto.UnmarshalOptions{Resolver: types}.Unmarshal(data, msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return msg, nil
+}
+
+// readPatch reads a patch written by the diff command.
+func readPatch(path string) (*linearize.Patch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch linearize.Patch
+	if err := patch.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("failed to parse patch %s: %w", path, err)
+	}
+	return &patch, nil
+}
+
+// writeMessage writes a message as binary or protojson.
+func writeMessage(w io.Writer, msg proto.Message, asJSON bool, types *dynamicpb.Types) error {
+	var data []byte
+	var err error
+	if asJSON {
+		data, err = protojson.MarshalOptions{Multiline: true, Resolver: types}.Marshal(msg)
+		data = append(data, '\n')
+	} else {
+		data, err = proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// flatten writes every leaf of the object as a path = value line.
+func flatten(w io.Writer, md protoreflect.MessageDescriptor, obj linearize.LinearizedObject) error {
+	var lines []string
+	flattenObject(&lines, "", md, obj)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flattenObject(lines *[]string, prefix string, md protoreflect.MessageDescriptor, obj linearize.LinearizedObject) {
+	keys := make([]int32, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, key := range keys {
+		fd := md.Fields().ByNumber(protoreflect.FieldNumber(key))
+		if fd == nil {
+			continue
+		}
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		switch value := obj[key].(type) {
+		case linearize.LinearizedSlice:
+			for i := 0; i < len(value); i++ {
+				flattenValue(lines, fmt.Sprintf("%s[%d]", path, i), fd, fd.Message(), value[int32(i)])
+			}
+		case linearize.LinearizedMap:
+			for i := 0; i < len(value); i++ {
+				entry := value[int32(i)]
+				key := fmt.Sprint(entry[0])
+				if s, ok := entry[0].(string); ok {
+					key = strconv.Quote(s)
+				}
+				flattenValue(lines, fmt.Sprintf("%s[%s]", path, key), fd.MapValue(), fd.MapValue().Message(), entry[1])
+			}
+		default:
+			flattenValue(lines, path, fd, fd.Message(), value)
+		}
+	}
+}
+
+func flattenValue(lines *[]string, path string, fd protoreflect.FieldDescriptor, md protoreflect.MessageDescriptor, value any) {
+	switch v := value.(type) {
+	case linearize.LinearizedObject:
+		if len(v) == 0 {
+			*lines = append(*lines, path+" = {}")
+			return
+		}
+		flattenObject(lines, path, md, v)
+	case string:
+		*lines = append(*lines, path+" = "+strconv.Quote(v))
+	case []byte:
+		*lines = append(*lines, fmt.Sprintf("%s = %q", path, v))
+	case protoreflect.EnumNumber:
+		name := strconv.Itoa(int(v))
+		if ev := fd.Enum().Values().ByNumber(v); ev != nil {
+			name = string(ev.Name())
+		}
+		*lines = append(*lines, path+" = "+name)
+	default:
+		*lines = append(*lines, fmt.Sprintf("%s = %v", path, v))
+	}
+}
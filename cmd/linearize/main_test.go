@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(mocks.File_mocks_proto),
+	}}
+	setData, err := proto.Marshal(set)
+	require.NoError(t, err)
+	descriptors := write(t, "mocks.binpb", setData)
+
+	previous := mocks.CreateComplexMessage()
+	previousData, err := proto.Marshal(previous)
+	require.NoError(t, err)
+	previousPath := write(t, "previous.binpb", previousData)
+
+	latest := mocks.CreateComplexMessage()
+	latest.Field1 = "changed_field1"
+	latest.Repeated = latest.Repeated[:1]
+	latestData, err := protojson.Marshal(latest)
+	require.NoError(t, err)
+	latestPath := write(t, "latest.json", latestData)
+
+	common := []string{"-I", descriptors, "--type", "mocks.Complex"}
+
+	t.Run("should flatten message", func(t *testing.T) {
+		// Arrange
+		var out strings.Builder
+
+		// Act
+		err := run(append(common, "flatten", previousPath), &out)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Field1 = \"complex_field1\"\n")
+		assert.Contains(t, out.String(), "Repeated[1].Repeated[0] = \"value1\"\n")
+		assert.Contains(t, out.String(), "Map[\"key2\"].Field2 = 42\n")
+	})
+
+	t.Run("should diff, render and apply patch", func(t *testing.T) {
+		// Arrange
+		patchPath := filepath.Join(dir, "patch.bin")
+		resultPath := filepath.Join(dir, "result.binpb")
+		require.NoError(t, run(append(common, "-o", patchPath, "diff", previousPath, latestPath), &strings.Builder{}))
+
+		// Act
+		var rendered strings.Builder
+		err := run(append(common, "render", patchPath), &rendered)
+		require.NoError(t, err)
+		err = run(append(common, "-o", resultPath, "apply", patchPath, previousPath), &strings.Builder{})
+		require.NoError(t, err)
+
+		// Assert
+		assert.Contains(t, rendered.String(), "Field1: UPDATE\n")
+		assert.Contains(t, rendered.String(), "Repeated[1]: REMOVE\n")
+
+		resultData, err := os.ReadFile(resultPath)
+		require.NoError(t, err)
+		var result mocks.Complex
+		require.NoError(t, proto.Unmarshal(resultData, &result))
+		assert.True(t, proto.Equal(latest, &result))
+	})
+
+	t.Run("should read messages in the format of their extension", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateComplexMessage()
+		msg.Field1 = strings.Repeat("x", '{')
+		data, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		require.Equal(t, "\n{", string(data[:2]))
+		path := write(t, "brace.binpb", data)
+		var out strings.Builder
+
+		// Act
+		err = run(append(common, "flatten", path), &out)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Field1 = \"xxx")
+	})
+
+	t.Run("should read messages in the given format", func(t *testing.T) {
+		// Arrange
+		path := write(t, "latest.txt", latestData)
+		var out strings.Builder
+
+		// Act
+		err := run(append(common, "-format", "json", "flatten", path), &out)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Field1 = \"changed_field1\"\n")
+	})
+
+	t.Run("should reject unknown message type", func(t *testing.T) {
+		// Act
+		err := run([]string{"-I", descriptors, "-type", "mocks.Missing", "flatten", previousPath}, &strings.Builder{})
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("should leave the output file untouched when the command fails", func(t *testing.T) {
+		for name, args := range map[string][]string{
+			"unknown command":  {"difff", previousPath, latestPath},
+			"missing operand":  {"diff", previousPath},
+			"unreadable input": {"diff", previousPath, filepath.Join(dir, "missing.binpb")},
+			"unknown format":   {"-format", "yaml", "diff", previousPath, latestPath},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				outputPath := write(t, "output.bin", []byte("keep"))
+
+				// Act
+				err := run(append(append(common, "-o", outputPath), args...), &strings.Builder{})
+
+				// Assert
+				assert.Error(t, err)
+				data, readErr := os.ReadFile(outputPath)
+				require.NoError(t, readErr)
+				assert.Equal(t, "keep", string(data))
+			})
+		}
+	})
+}
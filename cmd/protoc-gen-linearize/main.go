@@ -0,0 +1,922 @@
+// Command protoc-gen-linearize is a protoc plugin that generates reflection-free Linearize,
+// Unlinearize, Diff and Merge methods for the messages of proto3 files.
+//
+// Usage:
+//
+//	protoc --go_out=. --linearize_out=. --linearize_opt=paths=source_relative messages.proto
+//
+// The generated methods are picked up automatically by linearize.Linearize, linearize.Unlinearize,
+// linearize.DiffMessages and linearize.ApplyPatch. (linearize.field) options are read when the code is
+// generated; Diff and Merge take the same options as DiffMessages and MergeInto. Messages with a field
+// named like one of the methods are left to the reflective implementation.
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fgrzl/linearize/options"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const (
+	bytesPackage        = protogen.GoImportPath("bytes")
+	fmtPackage          = protogen.GoImportPath("fmt")
+	mapsPackage         = protogen.GoImportPath("maps")
+	mathPackage         = protogen.GoImportPath("math")
+	protoPackage        = protogen.GoImportPath("google.golang.org/protobuf/proto")
+	slicesPackage       = protogen.GoImportPath("slices")
+	linearizePackage    = protogen.GoImportPath("github.com/fgrzl/linearize")
+	protoreflectPackage = protogen.GoImportPath("google.golang.org/protobuf/reflect/protoreflect")
+)
+
+// methodNames are the generated methods, which must not clash with field names.
+var methodNames = map[string]bool{
+	"Linearize": true, "Unlinearize": true, "Diff": true, "DiffFields": true, "Merge": true, "MergeFields": true,
+}
+
+func main() {
+	protogen.Options{}.Run(run)
+}
+
+// run generates the files requested from the plugin.
+func run(gen *protogen.Plugin) error {
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	for _, f := range gen.Files {
+		if f.Generate {
+			generateFile(gen, f)
+		}
+	}
+	return nil
+}
+
+// generator writes the methods of one file.
+type generator struct {
+	gen *protogen.Plugin
+	g   *protogen.GeneratedFile
+}
+
+// failure returns the statement that returns an error expression from generated code.
+type failure func(err string) string
+
+// returnErr returns the error from a function returning only an error.
+func returnErr(err string) string {
+	return "return " + err
+}
+
+// returnNilErr returns the error from a function returning a value and an error.
+func returnNilErr(err string) string {
+	return "return nil, " + err
+}
+
+// generateFile writes <name>_linearize.pb.go for a proto3 file with at least one supported message.
+func generateFile(gen *protogen.Plugin, f *protogen.File) {
+	messages := supportedMessages(gen, f.Messages)
+	if len(messages) == 0 {
+		return
+	}
+
+	g := gen.NewGeneratedFile(f.GeneratedFilenamePrefix+"_linearize.pb.go", f.GoImportPath)
+	g.P("// Code generated by protoc-gen-linearize. DO NOT EDIT.")
+	g.P("// source: ", f.Desc.Path())
+	g.P()
+	g.P("package ", f.GoPackageName)
+	g.P()
+
+	w := generator{gen: gen, g: g}
+	for _, m := range messages {
+		w.linearize(m)
+		w.unlinearize(m)
+		w.diff(m)
+		w.equal(m)
+		w.merge(m)
+	}
+}
+
+// supportedMessages returns the messages, including nested ones, that get generated methods.
+func supportedMessages(gen *protogen.Plugin, messages []*protogen.Message) []*protogen.Message {
+	var supported []*protogen.Message
+	for _, m := range messages {
+		if generates(gen, m) {
+			supported = append(supported, m)
+		}
+		supported = append(supported, supportedMessages(gen, m.Messages)...)
+	}
+	return supported
+}
+
+// generates reports whether a message has generated methods, so that other generated code can
+// call them directly instead of going through the linearize package.
+func generates(gen *protogen.Plugin, m *protogen.Message) bool {
+	if m.Desc.IsMapEntry() || m.Desc.ParentFile().Syntax() != protoreflect.Proto3 {
+		return false
+	}
+	if f, ok := gen.FilesByPath[m.Desc.ParentFile().Path()]; !ok || !f.Generate {
+		return false
+	}
+	for _, field := range m.Fields {
+		if methodNames[field.GoName] {
+			return false
+		}
+	}
+	for _, oneof := range m.Oneofs {
+		if methodNames[oneof.GoName] {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldOptions returns the (linearize.field) options of a field, or nil when it has none.
+func fieldOptions(field *protogen.Field) *options.FieldOptions {
+	opts, _ := proto.GetExtension(field.Desc.Options(), options.E_Field).(*options.FieldOptions)
+	return opts
+}
+
+func (w generator) linearize(m *protogen.Message) {
+	g := w.g
+	g.P("// Linearize flattens the message into a LinearizedObject without reflection.")
+	g.P("func (x *", m.GoIdent, ") Linearize() (", linearizePackage.Ident("LinearizedObject"), ", error) {")
+	g.P("obj := make(", linearizePackage.Ident("LinearizedObject"), ")")
+	g.P("if x == nil {")
+	g.P("return obj, nil")
+	g.P("}")
+
+	for _, field := range m.Fields {
+		key := strconv.Itoa(int(field.Desc.Number()))
+		name := "x." + field.GoName
+		switch {
+		case field.Oneof != nil && !field.Oneof.Desc.IsSynthetic():
+			// Oneof members are written with the first member of their group
+			if field != field.Oneof.Fields[0] {
+				continue
+			}
+			var members []*protogen.Field
+			for _, member := range field.Oneof.Fields {
+				if !fieldOptions(member).GetDiffIgnore() {
+					members = append(members, member)
+				}
+			}
+			if len(members) == 0 {
+				continue
+			}
+			g.P("switch choice := x.", field.Oneof.GoName, ".(type) {")
+			for _, member := range members {
+				g.P("case *", member.GoIdent, ":")
+				w.linearizeValue("obj["+strconv.Itoa(int(member.Desc.Number()))+"]", "choice."+member.GoName, member, returnNilErr)
+			}
+			g.P("}")
+
+		case fieldOptions(field).GetDiffIgnore():
+			// Linearize leaves diff_ignore fields out
+
+		default:
+			value, set := w.fieldValue(name, field)
+			g.P("if ", set, " {")
+			w.linearizeField("obj["+key+"]", value, field, returnNilErr)
+			g.P("}")
+		}
+	}
+	g.P("return obj, nil")
+	g.P("}")
+	g.P()
+}
+
+// fieldValue returns the expression of the value of a field that is not part of a oneof, and the
+// condition under which protoreflect reports the field as set.
+func (w generator) fieldValue(name string, field *protogen.Field) (value, set string) {
+	switch {
+	case field.Desc.IsMap() || field.Desc.IsList():
+		return name, "len(" + name + ") > 0"
+	case field.Message != nil:
+		return name, name + " != nil"
+	case field.Desc.HasPresence():
+		return "*" + name, name + " != nil"
+	}
+	return name, w.populated(name, field)
+}
+
+// populated returns the condition under which protoreflect reports an implicit presence field as set.
+func (w generator) populated(name string, field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return name
+	case protoreflect.StringKind:
+		return name + ` != ""`
+	case protoreflect.BytesKind:
+		return "len(" + name + ") > 0"
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		// Negative zero is a set value
+		return name + " != 0 || " + w.g.QualifiedGoIdent(mathPackage.Ident("Signbit")) + "(float64(" + name + "))"
+	}
+	return name + " != 0"
+}
+
+// linearizeField assigns the linearized form of a set field value to target.
+func (w generator) linearizeField(target, value string, field *protogen.Field, fail failure) {
+	g := w.g
+	switch {
+	case field.Desc.IsMap():
+		keyField, valueField := field.Message.Fields[0], field.Message.Fields[1]
+		g.P("entries := make(", linearizePackage.Ident("LinearizedMap"), ", len(", value, "))")
+		g.P("for i, key := range ", linearizePackage.Ident("SortedMapKeys"), "(", value, ") {")
+		g.P("var value any")
+		w.linearizeValue("value", value+"[key]", valueField, fail)
+		g.P("entries[int32(i)] = [2]any{", w.scalar("key", keyField), ", value}")
+		g.P("}")
+		g.P(target, " = entries")
+
+	case field.Desc.IsList():
+		g.P("list := make(", linearizePackage.Ident("LinearizedSlice"), ", len(", value, "))")
+		g.P("for i, elem := range ", value, " {")
+		w.linearizeValue("list[int32(i)]", "elem", field, fail)
+		g.P("}")
+		g.P(target, " = list")
+
+	default:
+		w.linearizeValue(target, value, field, fail)
+	}
+}
+
+// linearizeValue assigns the linearized form of a value of the field's type to target.
+func (w generator) linearizeValue(target, value string, field *protogen.Field, fail failure) {
+	g := w.g
+	if field.Message == nil {
+		g.P(target, " = ", w.scalar(value, field))
+		return
+	}
+
+	if generates(w.gen, field.Message) {
+		g.P("nested, err := ", value, ".Linearize()")
+	} else {
+		g.P("nested, err := ", linearizePackage.Ident("Linearize"), "(", value, ")")
+	}
+	g.P("if err != nil {")
+	g.P(fail("err"))
+	g.P("}")
+	g.P(target, " = nested")
+}
+
+// scalar returns the expression of the linearized form of a scalar value.
+func (w generator) scalar(value string, field *protogen.Field) string {
+	if field.Desc.Kind() == protoreflect.EnumKind {
+		return w.g.QualifiedGoIdent(protoreflectPackage.Ident("EnumNumber")) + "(" + value + ")"
+	}
+	return value
+}
+
+func (w generator) unlinearize(m *protogen.Message) {
+	g := w.g
+	g.P("// Unlinearize populates the message from a LinearizedObject without reflection.")
+	g.P("func (x *", m.GoIdent, ") Unlinearize(obj ", linearizePackage.Ident("LinearizedObject"), ") error {")
+	g.P("for key, value := range obj {")
+	g.P("switch key {")
+
+	for _, field := range m.Fields {
+		g.P("case ", field.Desc.Number(), ":")
+		w.unlinearizeField(field, returnErr)
+	}
+
+	g.P("default:")
+	g.P("return ", fmtPackage.Ident("Errorf"), "(\"field number %d not found in the message\", key)")
+	g.P("}")
+	g.P("}")
+	g.P("return nil")
+	g.P("}")
+	g.P()
+}
+
+// unlinearizeField sets a field from the linearized value in the variable value, clearing it when
+// the value is nil.
+func (w generator) unlinearizeField(field *protogen.Field, fail failure) {
+	g := w.g
+	name := "x." + field.GoName
+	label := strconv.Quote(string(field.Desc.Name()))
+	switch {
+	case field.Oneof != nil && !field.Oneof.Desc.IsSynthetic():
+		oneof := "x." + field.Oneof.GoName
+		g.P("if value == nil {")
+		g.P("if _, ok := ", oneof, ".(*", field.GoIdent, "); ok {")
+		g.P(oneof, " = nil")
+		g.P("}")
+		g.P("} else {")
+		g.P("var v ", w.goType(field))
+		w.unlinearizeValue("v", "value", label, field, fail)
+		g.P(oneof, " = &", field.GoIdent, "{", field.GoName, ": v}")
+		g.P("}")
+
+	case field.Desc.IsMap():
+		keyField, valueField := field.Message.Fields[0], field.Message.Fields[1]
+		g.P("if value == nil {")
+		g.P(name, " = nil")
+		g.P("} else {")
+		g.P("entries, err := ", linearizePackage.Ident("As"), "[", linearizePackage.Ident("LinearizedMap"), "](value, ", label, ")")
+		g.P("if err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P(name, " = make(map[", w.goType(keyField), "]", w.goType(valueField), ", len(entries))")
+		g.P("for _, entry := range entries {")
+		g.P("var k ", w.goType(keyField))
+		g.P("{")
+		w.unlinearizeValue("k", "entry[0]", label, keyField, fail)
+		g.P("}")
+		g.P("var v ", w.goType(valueField))
+		w.unlinearizeValue("v", "entry[1]", label, valueField, fail)
+		g.P(name, "[k] = v")
+		g.P("}")
+		g.P("}")
+
+	case field.Desc.IsList():
+		g.P("if value == nil {")
+		g.P(name, " = nil")
+		g.P("} else {")
+		g.P("list, err := ", linearizePackage.Ident("As"), "[", linearizePackage.Ident("LinearizedSlice"), "](value, ", label, ")")
+		g.P("if err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P(name, " = make([]", w.goType(field), ", len(list))")
+		g.P("for i, elem := range list {")
+		g.P("if i < 0 || int(i) >= len(list) {")
+		g.P(fail(g.QualifiedGoIdent(fmtPackage.Ident("Errorf")) + "(\"field %s: index %d out of range\", " + label + ", i)"))
+		g.P("}")
+		w.unlinearizeValue(name+"[i]", "elem", label, field, fail)
+		g.P("}")
+		g.P("}")
+
+	case field.Message != nil:
+		// Existing messages are populated in place, like Unlinearize does
+		g.P("if value == nil {")
+		g.P(name, " = nil")
+		g.P("} else {")
+		g.P("nested, err := ", linearizePackage.Ident("As"), "[", linearizePackage.Ident("LinearizedObject"), "](value, ", label, ")")
+		g.P("if err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P("if ", name, " == nil {")
+		g.P(name, " = new(", field.Message.GoIdent, ")")
+		g.P("}")
+		w.unlinearizeMessage(name, "nested", field.Message, fail)
+		g.P("}")
+
+	case field.Desc.HasPresence():
+		g.P("if value == nil {")
+		g.P(name, " = nil")
+		g.P("} else {")
+		g.P("var v ", w.goType(field))
+		w.unlinearizeValue("v", "value", label, field, fail)
+		g.P(name, " = &v")
+		g.P("}")
+
+	default:
+		g.P("if value == nil {")
+		g.P(name, " = ", w.zero(field))
+		g.P("} else {")
+		w.unlinearizeValue(name, "value", label, field, fail)
+		g.P("}")
+	}
+}
+
+// unlinearizeValue converts a linearized value to the field's type and assigns it to target.
+func (w generator) unlinearizeValue(target, value, label string, field *protogen.Field, fail failure) {
+	g := w.g
+	switch {
+	case field.Message != nil:
+		g.P("nested, err := ", linearizePackage.Ident("As"), "[", linearizePackage.Ident("LinearizedObject"), "](", value, ", ", label, ")")
+		g.P("if err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P(target, " = new(", field.Message.GoIdent, ")")
+		w.unlinearizeMessage(target, "nested", field.Message, fail)
+	case field.Desc.Kind() == protoreflect.EnumKind:
+		g.P("converted, err := ", linearizePackage.Ident("EnumAs"), "[", field.Enum.GoIdent, "](", value, ", ", label, ")")
+		g.P("if err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P(target, " = converted")
+	default:
+		g.P("converted, err := ", linearizePackage.Ident("As"), "[", w.goType(field), "](", value, ", ", label, ")")
+		g.P("if err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P(target, " = converted")
+	}
+}
+
+// unlinearizeMessage populates an allocated message from a LinearizedObject.
+func (w generator) unlinearizeMessage(target, obj string, m *protogen.Message, fail failure) {
+	g := w.g
+	if generates(w.gen, m) {
+		g.P("if err := ", target, ".Unlinearize(", obj, "); err != nil {")
+	} else {
+		g.P("if err := ", linearizePackage.Ident("Unlinearize"), "(", obj, ", ", target, "); err != nil {")
+	}
+	g.P(fail("err"))
+	g.P("}")
+}
+
+func (w generator) diff(m *protogen.Message) {
+	g := w.g
+	patch := linearizePackage.Ident("Patch")
+	g.P("// Diff compares the message with a later version of it without reflection. It returns the same")
+	g.P("// patch as linearize.DiffMessages.")
+	g.P("func (x *", m.GoIdent, ") Diff(latest *", m.GoIdent, ", opts ...", linearizePackage.Ident("DiffOption"), ") (", patch, ", error) {")
+	g.P("d := ", linearizePackage.Ident("NewMessageDiff"), "(x.ProtoReflect().Descriptor(), opts...)")
+	g.P("if err := x.DiffFields(latest, d); err != nil {")
+	g.P("return ", patch, "{}, err")
+	g.P("}")
+	g.P("return d.Patch()")
+	g.P("}")
+	g.P()
+
+	g.P("// DiffFields reports the fields of the message and of a later version of it to d.")
+	g.P("func (x *", m.GoIdent, ") DiffFields(latest *", m.GoIdent, ", d *", linearizePackage.Ident("MessageDiff"), ") error {")
+	g.P("if x == nil {")
+	g.P("x = new(", m.GoIdent, ")")
+	g.P("}")
+	g.P("if latest == nil {")
+	g.P("latest = new(", m.GoIdent, ")")
+	g.P("}")
+	for _, field := range m.Fields {
+		if fieldOptions(field).GetDiffIgnore() {
+			// Diff leaves diff_ignore fields out
+			continue
+		}
+		g.P("{")
+		if field.Oneof != nil && !field.Oneof.Desc.IsSynthetic() {
+			g.P("prevChoice, p := x.", field.Oneof.GoName, ".(*", field.GoIdent, ")")
+			g.P("latestChoice, l := latest.", field.Oneof.GoName, ".(*", field.GoIdent, ")")
+			w.diffField("prevChoice."+field.GoName, "latestChoice."+field.GoName, field)
+		} else {
+			prev, prevSet := w.fieldValue("x."+field.GoName, field)
+			latest, latestSet := w.fieldValue("latest."+field.GoName, field)
+			g.P("p, l := ", prevSet, ", ", latestSet)
+			w.diffField(prev, latest, field)
+		}
+		g.P("}")
+	}
+	g.P("return nil")
+	g.P("}")
+	g.P()
+}
+
+// diffField reports a field to d. The variables p and l tell whether it is set in each message.
+// Messages and lists of messages with generated methods are compared in lockstep; other fields are
+// linearized when they may have changed.
+func (w generator) diffField(prev, latest string, field *protogen.Field) {
+	g := w.g
+	key := strconv.Itoa(int(field.Desc.Number()))
+	opts := fieldOptions(field)
+	lockstep := field.Message != nil && !field.Desc.IsMap() && !opts.GetAtomic() && generates(w.gen, field.Message)
+	switch {
+	case lockstep && !field.Desc.IsList():
+		// Equal values are skipped like protoreflect skips them
+		g.P("if !p || !l || d.VisitsEqual() || ", w.differs(prev, latest, field), " {")
+		g.P("var child *", linearizePackage.Ident("MessageDiff"))
+		g.P("if p && l {")
+		g.P("child = d.Message(", key, ")")
+		g.P("}")
+		g.P("if child != nil {")
+		g.P("if err := ", prev, ".DiffFields(", latest, ", child); err != nil {")
+		g.P("return err")
+		g.P("}")
+		g.P("if child.Changed() {")
+		w.linearizeBoth(prev, latest)
+		g.P("d.EndMessage(", key, ", child, prev, next)")
+		g.P("}")
+		g.P("} else if p != l {")
+		w.diffValues(key, prev, latest, field)
+		g.P("}")
+		g.P("}")
+
+	case lockstep && opts.GetListKey() == "":
+		g.P("if !p || !l || d.VisitsEqual() || ", w.differs(prev, latest, field), " {")
+		g.P("var elems *", linearizePackage.Ident("ListDiff"))
+		g.P("if p && l {")
+		g.P("elems = d.List(", key, ", len(", prev, "), len(", latest, "))")
+		g.P("}")
+		g.P("if elems != nil {")
+		g.P("for i := 0; i < elems.Len(); i++ {")
+		g.P("switch {")
+		g.P("case i >= len(", prev, "):")
+		g.P("next, err := ", latest, "[i].Linearize()")
+		g.P("if err != nil {")
+		g.P("return err")
+		g.P("}")
+		g.P("elems.Change(i, nil, next)")
+		g.P("case i >= len(", latest, "):")
+		g.P("prev, err := ", prev, "[i].Linearize()")
+		g.P("if err != nil {")
+		g.P("return err")
+		g.P("}")
+		g.P("elems.Change(i, prev, nil)")
+		g.P("case !d.VisitsEqual() && ", prev, "[i].equal(", latest, "[i]):")
+		g.P("default:")
+		g.P("elem := elems.Element()")
+		g.P("if err := ", prev, "[i].DiffFields(", latest, "[i], elem); err != nil {")
+		g.P("return err")
+		g.P("}")
+		g.P("if elem.Changed() {")
+		w.linearizeBoth(prev+"[i]", latest+"[i]")
+		g.P("elems.EndElement(i, elem, prev, next)")
+		g.P("}")
+		g.P("}")
+		g.P("}")
+		g.P("if elems.Changed() {")
+		g.P("var prev ", linearizePackage.Ident("LinearizedSlice"))
+		w.linearizeField("prev", prev, field, returnErr)
+		g.P("d.EndList(", key, ", elems, prev)")
+		g.P("}")
+		g.P("} else if p || l {")
+		w.diffValues(key, prev, latest, field)
+		g.P("}")
+		g.P("}")
+
+	default:
+		g.P("if p || l {")
+		g.P("if p != l || d.VisitsEqual() || ", w.differs(prev, latest, field), " {")
+		w.diffValues(key, prev, latest, field)
+		g.P("}")
+		g.P("}")
+	}
+}
+
+// linearizeBoth declares prev and next as the linearized forms of two messages with generated methods.
+func (w generator) linearizeBoth(prev, latest string) {
+	g := w.g
+	g.P("prev, err := ", prev, ".Linearize()")
+	g.P("if err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("next, err := ", latest, ".Linearize()")
+	g.P("if err != nil {")
+	g.P("return err")
+	g.P("}")
+}
+
+// diffValues linearizes the set values of a field and reports them to d.
+func (w generator) diffValues(key, prev, latest string, field *protogen.Field) {
+	g := w.g
+	g.P("var prev, next any")
+	g.P("if p {")
+	w.linearizeField("prev", prev, field, returnErr)
+	g.P("}")
+	g.P("if l {")
+	w.linearizeField("next", latest, field, returnErr)
+	g.P("}")
+	g.P("d.Field(", key, ", p, l, prev, next)")
+}
+
+// differs returns the negation of equalValues.
+func (w generator) differs(prev, latest string, field *protogen.Field) string {
+	if field.Message == nil && !field.Desc.IsList() && field.Desc.Kind() != protoreflect.BytesKind {
+		return prev + " != " + latest
+	}
+	return "!" + w.equalValues(prev, latest, field)
+}
+
+// equalValues returns a condition that holds only when two set values of a field linearize to
+// equal values. It may fail for values that do, which only costs a comparison.
+func (w generator) equalValues(prev, latest string, field *protogen.Field) string {
+	switch {
+	case field.Desc.IsMap():
+		if equal := w.elemEqual(field.Message.Fields[1]); equal != "" {
+			return w.g.QualifiedGoIdent(mapsPackage.Ident("EqualFunc")) + "(" + prev + ", " + latest + ", " + equal + ")"
+		}
+		return w.g.QualifiedGoIdent(mapsPackage.Ident("Equal")) + "(" + prev + ", " + latest + ")"
+	case field.Desc.IsList():
+		if equal := w.elemEqual(field); equal != "" {
+			return w.g.QualifiedGoIdent(slicesPackage.Ident("EqualFunc")) + "(" + prev + ", " + latest + ", " + equal + ")"
+		}
+		return w.g.QualifiedGoIdent(slicesPackage.Ident("Equal")) + "(" + prev + ", " + latest + ")"
+	case field.Message != nil && generates(w.gen, field.Message):
+		return prev + ".equal(" + latest + ")"
+	case field.Message != nil:
+		return w.g.QualifiedGoIdent(protoPackage.Ident("Equal")) + "(" + prev + ", " + latest + ")"
+	case field.Desc.Kind() == protoreflect.BytesKind:
+		return w.g.QualifiedGoIdent(bytesPackage.Ident("Equal")) + "(" + prev + ", " + latest + ")"
+	}
+	return prev + " == " + latest
+}
+
+// elemEqual returns the function comparing two elements of a repeated or map field, or "" when
+// they are compared with ==.
+func (w generator) elemEqual(field *protogen.Field) string {
+	switch {
+	case field.Message != nil && generates(w.gen, field.Message):
+		return "(*" + w.g.QualifiedGoIdent(field.Message.GoIdent) + ").equal"
+	case field.Message != nil:
+		return "func(a, b *" + w.g.QualifiedGoIdent(field.Message.GoIdent) + ") bool { return " +
+			w.g.QualifiedGoIdent(protoPackage.Ident("Equal")) + "(a, b) }"
+	case field.Desc.Kind() == protoreflect.BytesKind:
+		return w.g.QualifiedGoIdent(bytesPackage.Ident("Equal"))
+	}
+	return ""
+}
+
+func (w generator) equal(m *protogen.Message) {
+	g := w.g
+	g.P("// equal reports whether two messages linearize to equal objects. It may report false for messages")
+	g.P("// that do, which only makes Diff compare them.")
+	g.P("func (x *", m.GoIdent, ") equal(y *", m.GoIdent, ") bool {")
+	g.P("if x == nil || y == nil {")
+	g.P("return x == y")
+	g.P("}")
+	for _, field := range m.Fields {
+		switch {
+		case field.Oneof != nil && !field.Oneof.Desc.IsSynthetic():
+			if field != field.Oneof.Fields[0] {
+				continue
+			}
+			g.P("switch choice := x.", field.Oneof.GoName, ".(type) {")
+			g.P("case nil:")
+			g.P("if y.", field.Oneof.GoName, " != nil {")
+			g.P("return false")
+			g.P("}")
+			for _, member := range field.Oneof.Fields {
+				g.P("case *", member.GoIdent, ":")
+				g.P("other, ok := y.", field.Oneof.GoName, ".(*", member.GoIdent, ")")
+				g.P("if !ok || ", w.differs("choice."+member.GoName, "other."+member.GoName, member), " {")
+				g.P("return false")
+				g.P("}")
+			}
+			g.P("}")
+
+		case fieldOptions(field).GetDiffIgnore():
+			// Linearize leaves diff_ignore fields out
+
+		case field.Desc.HasPresence() && field.Message == nil:
+			name := field.GoName
+			g.P("if (x.", name, " == nil) != (y.", name, " == nil) || x.", name, " != nil && *x.", name, " != *y.", name, " {")
+			g.P("return false")
+			g.P("}")
+
+		default:
+			name := field.GoName
+			differs := w.differs("x."+name, "y."+name, field)
+			if kind := field.Desc.Kind(); !field.Desc.IsList() && (kind == protoreflect.FloatKind || kind == protoreflect.DoubleKind) {
+				// Negative zero is set and positive zero is not
+				signbit := w.g.QualifiedGoIdent(mathPackage.Ident("Signbit"))
+				differs += " || " + signbit + "(float64(x." + name + ")) != " + signbit + "(float64(y." + name + "))"
+			}
+			g.P("if ", differs, " {")
+			g.P("return false")
+			g.P("}")
+		}
+	}
+	g.P("return true")
+	g.P("}")
+	g.P()
+}
+
+func (w generator) merge(m *protogen.Message) {
+	g := w.g
+	mask := linearizePackage.Ident("UpdateMask")
+	obj := linearizePackage.Ident("LinearizedObject")
+	g.P("// Merge applies the operations of an UpdateMask to the message in place without reflection. It has")
+	g.P("// the same effect as linearize.MergeInto, which it falls back to when the options filter fields or")
+	g.P("// check the result, or when the message or a message nested in it has a diff_ignore field.")
+	g.P("func (x *", m.GoIdent, ") Merge(mask *", mask, ", diff ", obj, ", opts ...", linearizePackage.Ident("MergeOption"), ") error {")
+	g.P("m := ", linearizePackage.Ident("NewMessageMerge"), "(x.ProtoReflect().Descriptor(), opts...)")
+	g.P("if m == nil {")
+	g.P("return ", linearizePackage.Ident("MergeInto"), "(x, mask, diff, opts...)")
+	g.P("}")
+	g.P("if err := m.Check(mask, diff); err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("return x.MergeFields(mask, diff, m)")
+	g.P("}")
+	g.P()
+
+	g.P("// MergeFields applies the operations of an UpdateMask to the fields of the message.")
+	g.P("func (x *", m.GoIdent, ") MergeFields(mask *", mask, ", diff ", obj, ", m *", linearizePackage.Ident("MessageMerge"), ") error {")
+	g.P("for _, pos := range ", linearizePackage.Ident("SortedPositions"), "(mask) {")
+	g.P("if err := x.mergeField(pos, mask.Values[pos], diff, m); err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("}")
+	g.P("return nil")
+	g.P("}")
+	g.P()
+
+	g.P("// mergeField applies the operation on one field. Removed fields are set from a nil value.")
+	g.P("func (x *", m.GoIdent, ") mergeField(pos int32, maskValue *", linearizePackage.Ident("UpdateMaskValue"), ", diff ", obj, ", m *", linearizePackage.Ident("MessageMerge"), ") error {")
+	g.P("value, exists := diff[pos]")
+	g.P("masks := maskValue.Masks")
+	g.P("switch maskValue.Op {")
+	g.P("case ", linearizePackage.Ident("UpdateMaskOperation_REMOVE"), ":")
+	g.P("value, exists, masks = nil, true, nil")
+	g.P("case ", linearizePackage.Ident("UpdateMaskOperation_ADD"), ", ", linearizePackage.Ident("UpdateMaskOperation_UPDATE"), ":")
+	g.P("default:")
+	g.P("exists, masks = false, nil")
+	g.P("}")
+	g.P("switch pos {")
+	for _, field := range m.Fields {
+		g.P("case ", field.Desc.Number(), ":")
+		w.mergeField(field)
+	}
+	g.P("default:")
+	g.P("return ", fmtPackage.Ident("Errorf"), "(\"field number %d not found in the message\", pos)")
+	g.P("}")
+	g.P("return nil")
+	g.P("}")
+	g.P()
+}
+
+// mergeField applies the mask in masks to a field, or sets the whole field from value when masks is nil.
+func (w generator) mergeField(field *protogen.Field) {
+	g := w.g
+	label := strconv.Quote(string(field.Desc.Name()))
+	errorf := g.QualifiedGoIdent(fmtPackage.Ident("Errorf"))
+	fail := func(err string) string {
+		return "return " + errorf + "(\"failed to merge field %s: %w\", " + label + ", " + err + ")"
+	}
+
+	if field.Message == nil && !field.Desc.IsList() {
+		// Scalars are always set whole
+		g.P("if masks == nil && !exists {")
+		g.P("return nil")
+		g.P("}")
+		w.unlinearizeField(field, fail)
+		return
+	}
+
+	g.P("if masks == nil {")
+	g.P("if exists {")
+	w.unlinearizeField(field, fail)
+	g.P("}")
+	g.P("return nil")
+	g.P("}")
+
+	name := "x." + field.GoName
+	switch {
+	case field.Desc.IsMap():
+		keyField, valueField := field.Message.Fields[0], field.Message.Fields[1]
+		g.P("entries, ok := value.(", linearizePackage.Ident("LinearizedMap"), ")")
+		g.P("if !ok {")
+		g.P("return ", errorf, "(\"field %s: expected map in diff but got %T\", ", label, ", value)")
+		g.P("}")
+		g.P("if ", name, " == nil {")
+		g.P(name, " = make(map[", w.goType(keyField), "]", w.goType(valueField), ")")
+		g.P("}")
+		g.P("keys := ", linearizePackage.Ident("OrderedMapKeys"), "(", name, ", m.MapKeyOrder())")
+		g.P("// Entries are removed before they are set, so a key that moves position is not lost")
+		g.P("var updates []int32")
+		g.P("for _, position := range ", linearizePackage.Ident("SortedPositions"), "(masks) {")
+		g.P("if int(position) < len(keys) {")
+		g.P("delete(", name, ", keys[position])")
+		g.P("}")
+		g.P("if masks.Values[position].Op != ", linearizePackage.Ident("UpdateMaskOperation_REMOVE"), " {")
+		g.P("updates = append(updates, position)")
+		g.P("}")
+		g.P("}")
+		g.P("for _, position := range updates {")
+		g.P("entry, exists := entries[position]")
+		g.P("if !exists {")
+		g.P("continue")
+		g.P("}")
+		g.P("var k ", w.goType(keyField))
+		g.P("{")
+		w.unlinearizeValue("k", "entry[0]", label, keyField, func(err string) string {
+			return fail(errorf + "(\"failed to set map key %v: %w\", entry[0], " + err + ")")
+		})
+		g.P("}")
+		g.P("var v ", w.goType(valueField))
+		w.unlinearizeValue("v", "entry[1]", label, valueField, func(err string) string {
+			return fail(errorf + "(\"failed to set map value for key %v: %w\", entry[0], " + err + ")")
+		})
+		g.P(name, "[k] = v")
+		g.P("}")
+
+	case field.Desc.IsList():
+		g.P("list, ok := value.(", linearizePackage.Ident("LinearizedSlice"), ")")
+		g.P("if !ok {")
+		g.P("return ", errorf, "(\"field %s: expected slice in diff but got %T\", ", label, ", value)")
+		g.P("}")
+		g.P("if masks.GetListKey() != 0 {")
+		g.P("// Keyed lists are rebuilt by key from their linearized elements")
+		g.P("current := make(", linearizePackage.Ident("LinearizedSlice"), ", len(", name, "))")
+		g.P("for i, elem := range ", name, " {")
+		if field.Message != nil {
+			g.P("obj, err := m.Linearize(elem)")
+			g.P("if err != nil {")
+			g.P(fail("err"))
+			g.P("}")
+			g.P("current[int32(i)] = obj")
+		} else {
+			w.linearizeValue("current[int32(i)]", "elem", field, fail)
+		}
+		g.P("}")
+		g.P("if err := m.KeyedList(masks, current, list); err != nil {")
+		g.P(fail("err"))
+		g.P("}")
+		g.P("value = current")
+		w.unlinearizeField(field, fail)
+		g.P("return nil")
+		g.P("}")
+		g.P("var removed map[int32]bool")
+		g.P("for _, index := range ", linearizePackage.Ident("SortedPositions"), "(masks) {")
+		g.P("if masks.Values[index].Op == ", linearizePackage.Ident("UpdateMaskOperation_REMOVE"), " {")
+		g.P("if removed == nil {")
+		g.P("removed = make(map[int32]bool)")
+		g.P("}")
+		g.P("removed[index] = true")
+		g.P("continue")
+		g.P("}")
+		g.P("elem, exists := list[index]")
+		g.P("if !exists {")
+		g.P("continue")
+		g.P("}")
+		g.P("if int(index) > len(", name, ") {")
+		g.P(fail(errorf + "(\"index %d is past the end of the list\", index)"))
+		g.P("}")
+		g.P("var v ", w.goType(field))
+		w.unlinearizeValue("v", "elem", label, field, func(err string) string {
+			return fail(errorf + "(\"failed to set list element at index %d: %w\", index, " + err + ")")
+		})
+		g.P("if int(index) == len(", name, ") {")
+		g.P(name, " = append(", name, ", v)")
+		g.P("} else {")
+		g.P(name, "[index] = v")
+		g.P("}")
+		g.P("}")
+		g.P("if len(removed) > 0 {")
+		g.P("kept := make([]", w.goType(field), ", 0, len(", name, "))")
+		g.P("for i, elem := range ", name, " {")
+		g.P("if !removed[int32(i)] {")
+		g.P("kept = append(kept, elem)")
+		g.P("}")
+		g.P("}")
+		g.P(name, " = kept")
+		g.P("}")
+
+	default:
+		g.P("obj, ok := value.(", linearizePackage.Ident("LinearizedObject"), ")")
+		g.P("if !ok {")
+		g.P("return ", errorf, "(\"field %s: expected object in diff but got %T\", ", label, ", value)")
+		g.P("}")
+		if field.Oneof != nil && !field.Oneof.Desc.IsSynthetic() {
+			// Merging into a oneof member that is not set replaces the other members
+			g.P("choice, ok := x.", field.Oneof.GoName, ".(*", field.GoIdent, ")")
+			g.P("if !ok {")
+			g.P("choice = &", field.GoIdent, "{}")
+			g.P("x.", field.Oneof.GoName, " = choice")
+			g.P("}")
+			name = "choice." + field.GoName
+		}
+		g.P("if ", name, " == nil {")
+		g.P(name, " = new(", field.Message.GoIdent, ")")
+		g.P("}")
+		if generates(w.gen, field.Message) {
+			g.P("if err := ", name, ".MergeFields(masks, obj, m); err != nil {")
+		} else {
+			g.P("if err := m.Message(", name, ", masks, obj); err != nil {")
+		}
+		g.P(fail("err"))
+		g.P("}")
+	}
+}
+
+// goType returns the Go type of a singular value of the field.
+func (w generator) goType(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.EnumKind:
+		return w.g.QualifiedGoIdent(field.Enum.GoIdent)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.StringKind:
+		return "string"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "*" + w.g.QualifiedGoIdent(field.Message.GoIdent)
+	}
+	panic(fmt.Sprintf("unsupported field kind %s", field.Desc.Kind()))
+}
+
+// zero returns the zero value of an implicit presence scalar field.
+func (w generator) zero(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "false"
+	case protoreflect.StringKind:
+		return `""`
+	case protoreflect.BytesKind:
+		return "nil"
+	}
+	return "0"
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/fgrzl/linearize/mocks/generated"
+	"github.com/fgrzl/linearize/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func generate(t *testing.T, req *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	gen, err := protogen.Options{}.New(req)
+	require.NoError(t, err)
+	require.NoError(t, run(gen))
+	return gen.Response()
+}
+
+func TestGenerator(t *testing.T) {
+	t.Run("should generate the checked in mocks", func(t *testing.T) {
+		// Arrange
+		req := &pluginpb.CodeGeneratorRequest{
+			FileToGenerate: []string{"generated.proto"},
+			Parameter:      proto.String("paths=source_relative"),
+			ProtoFile: []*descriptorpb.FileDescriptorProto{
+				protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+				protodesc.ToFileDescriptorProto(options.File_linearize_options_proto),
+				protodesc.ToFileDescriptorProto(mocks.File_mocks_proto),
+				protodesc.ToFileDescriptorProto(generated.File_generated_proto),
+			},
+		}
+		expected, err := os.ReadFile("../../mocks/generated/generated_linearize.pb.go")
+		require.NoError(t, err)
+
+		// Act
+		resp := generate(t, req)
+
+		// Assert
+		require.Nil(t, resp.Error)
+		require.Len(t, resp.File, 1)
+		assert.Equal(t, "generated_linearize.pb.go", resp.File[0].GetName())
+		assert.Equal(t, string(expected), resp.File[0].GetContent())
+	})
+
+	t.Run("should skip proto2 files", func(t *testing.T) {
+		// Arrange
+		req := &pluginpb.CodeGeneratorRequest{
+			FileToGenerate: []string{"legacy.proto"},
+			ProtoFile: []*descriptorpb.FileDescriptorProto{{
+				Name:    proto.String("legacy.proto"),
+				Package: proto.String("legacy"),
+				Syntax:  proto.String("proto2"),
+				Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/legacy")},
+				MessageType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("Legacy"),
+					Field: []*descriptorpb.FieldDescriptorProto{{
+						Name:   proto.String("name"),
+						Number: proto.Int32(1),
+						Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					}},
+				}},
+			}},
+		}
+
+		// Act
+		resp := generate(t, req)
+
+		// Assert
+		require.Nil(t, resp.Error)
+		assert.Empty(t, resp.File)
+	})
+
+	t.Run("should skip messages with fields named like the methods", func(t *testing.T) {
+		// Arrange
+		req := &pluginpb.CodeGeneratorRequest{
+			FileToGenerate: []string{"clash.proto"},
+			ProtoFile: []*descriptorpb.FileDescriptorProto{{
+				Name:    proto.String("clash.proto"),
+				Package: proto.String("clash"),
+				Syntax:  proto.String("proto3"),
+				Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/clash")},
+				MessageType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("Clash"),
+					Field: []*descriptorpb.FieldDescriptorProto{{
+						Name:     proto.String("Unlinearize"),
+						JsonName: proto.String("Unlinearize"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					}},
+				}},
+			}},
+		}
+
+		// Act
+		resp := generate(t, req)
+
+		// Assert
+		require.Nil(t, resp.Error)
+		assert.Empty(t, resp.File)
+	})
+}
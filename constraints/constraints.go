@@ -0,0 +1,123 @@
+// Package constraints evaluates the buf.validate constraints declared on a message against
+// linearized state, so Merge can reject patches that produce invalid messages.
+package constraints
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/fgrzl/linearize"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrViolation is matched by errors reporting that a message violates its constraints.
+var ErrViolation = errors.New("constraint violation")
+
+// Error lists the violated constraints. Violation paths use field numbers, slice indices
+// and map keys like the rest of the package; Path.Format renders them with field names.
+// An UpdateMask addresses map entries by their position in a linearized object instead of
+// their key, so linearize.ParsePaths converts formatted violation paths to a mask given the
+// object that was validated.
+type Error struct {
+	Violations []linearize.Violation
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrViolation, strings.Join(messages, "; "))
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrViolation
+}
+
+// Check returns a MergeOption that unlinearizes the merged object into a new message of the
+// template's type and evaluates its constraints. A failing patch is rejected with an *Error.
+func Check(validator *protovalidate.Validator, template proto.Message) linearize.MergeOption {
+	return linearize.WithCheck(func(merged linearize.LinearizedObject) error {
+		return Validate(validator, merged, template)
+	})
+}
+
+// Validate unlinearizes the object into a new message of the template's type and evaluates its
+// constraints. It returns an *Error when constraints are violated.
+func Validate(validator *protovalidate.Validator, obj linearize.LinearizedObject, template proto.Message) error {
+	msg := template.ProtoReflect().New().Interface()
+	if err := linearize.Unlinearize(obj, msg); err != nil {
+		return err
+	}
+
+	err := validator.Validate(msg)
+	var validationErr *protovalidate.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	md := msg.ProtoReflect().Descriptor()
+	violations := make([]linearize.Violation, 0, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		violations = append(violations, linearize.Violation{
+			Path:    fieldPath(violation.Proto.GetField().GetElements(), md),
+			Message: fmt.Sprintf("%s [%s]", violation.Proto.GetMessage(), violation.Proto.GetConstraintId()),
+		})
+	}
+	return &Error{Violations: violations}
+}
+
+// fieldPath converts a protovalidate field path to a Path, typing map keys the way Linearize does.
+func fieldPath(elements []*validate.FieldPathElement, md protoreflect.MessageDescriptor) linearize.Path {
+	var path linearize.Path
+	for _, elem := range elements {
+		path = path.Field(elem.GetFieldNumber())
+
+		var fd protoreflect.FieldDescriptor
+		if md != nil {
+			fd = md.Fields().ByNumber(protoreflect.FieldNumber(elem.GetFieldNumber()))
+		}
+		md = nil
+		if fd != nil {
+			md = fd.Message()
+			if fd.IsMap() {
+				md = fd.MapValue().Message()
+			}
+		}
+
+		switch subscript := elem.GetSubscript().(type) {
+		case *validate.FieldPathElement_Index:
+			path = path.Index(int32(subscript.Index))
+		case *validate.FieldPathElement_BoolKey:
+			path = path.Key(subscript.BoolKey)
+		case *validate.FieldPathElement_StringKey:
+			path = path.Key(subscript.StringKey)
+		case *validate.FieldPathElement_IntKey:
+			if fd != nil && fd.IsMap() && is32Bit(fd.MapKey().Kind()) {
+				path = path.Key(int32(subscript.IntKey))
+			} else {
+				path = path.Key(subscript.IntKey)
+			}
+		case *validate.FieldPathElement_UintKey:
+			if fd != nil && fd.IsMap() && is32Bit(fd.MapKey().Kind()) {
+				path = path.Key(uint32(subscript.UintKey))
+			} else {
+				path = path.Key(subscript.UintKey)
+			}
+		}
+	}
+	return path
+}
+
+func is32Bit(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return true
+	}
+	return false
+}
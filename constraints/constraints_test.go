@@ -0,0 +1,107 @@
+package constraints
+
+import (
+	"testing"
+
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/fgrzl/linearize"
+	"github.com/fgrzl/linearize/constraints/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraints(t *testing.T) {
+	md := (&mocks.Validated{}).ProtoReflect().Descriptor()
+	validator, err := protovalidate.New()
+	require.NoError(t, err)
+
+	linearizeValidated := func(t *testing.T) linearize.LinearizedObject {
+		linearized, err := linearize.Linearize(mocks.CreateValidatedMessage())
+		require.NoError(t, err)
+		return linearized
+	}
+
+	t.Run("should apply patches that satisfy constraints", func(t *testing.T) {
+		// Arrange
+		current := linearizeValidated(t)
+		latest := mocks.CreateValidatedMessage()
+		latest.Age = 31
+		latest.Items[1].Name = "renamed"
+		after, err := linearize.Linearize(latest)
+		require.NoError(t, err)
+		_, diff, mask, err := linearize.Diff(current, after)
+		require.NoError(t, err)
+
+		// Act
+		err = linearize.Merge(mask, current, diff, Check(validator, &mocks.Validated{}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, after.Equal(current))
+	})
+
+	t.Run("should reject patches that violate constraints", func(t *testing.T) {
+		// Arrange
+		current := linearizeValidated(t)
+		original := current.Clone()
+		latest := mocks.CreateValidatedMessage()
+		latest.Age = 200
+		latest.Nested = nil
+		latest.Items[1].Name = ""
+		latest.Map["key1"].Name = ""
+		after, err := linearize.Linearize(latest)
+		require.NoError(t, err)
+		_, diff, mask, err := linearize.Diff(current, after)
+		require.NoError(t, err)
+
+		// Act
+		err = linearize.Merge(mask, current, diff, Check(validator, &mocks.Validated{}))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrViolation)
+		assert.True(t, original.Equal(current))
+
+		var constraintErr *Error
+		require.ErrorAs(t, err, &constraintErr)
+		paths := make([]string, len(constraintErr.Violations))
+		for i, violation := range constraintErr.Violations {
+			paths[i] = violation.Path.Format(md)
+		}
+		assert.ElementsMatch(t, []string{"Age", "Nested", "Items[1].Name", `Map["key1"].Name`}, paths)
+
+		// Paths use field numbers like the mask
+		for _, violation := range constraintErr.Violations {
+			if violation.Path.Format(md) == "Items[1].Name" {
+				assert.Equal(t, "4[1].1", violation.Path.String())
+				assert.Contains(t, violation.Message, "string.min_len")
+			}
+		}
+	})
+
+	t.Run("should convert violation paths to mask positions", func(t *testing.T) {
+		// Arrange
+		current := linearizeValidated(t)
+		latest := mocks.CreateValidatedMessage()
+		latest.Map["key0"] = &mocks.ValidatedItem{Name: "first"}
+		latest.Map["key1"].Name = ""
+		after, err := linearize.Linearize(latest)
+		require.NoError(t, err)
+		_, _, diffMask, err := linearize.Diff(current, after)
+		require.NoError(t, err)
+
+		// Act
+		err = Validate(validator, after, &mocks.Validated{})
+
+		// Assert
+		var constraintErr *Error
+		require.ErrorAs(t, err, &constraintErr)
+		require.Len(t, constraintErr.Violations, 1)
+		violation := constraintErr.Violations[0]
+		assert.Equal(t, `5["key1"].1`, violation.Path.String())
+		mask, err := linearize.ParsePaths([]string{violation.Path.Format(md)}, md, after)
+		require.NoError(t, err)
+		assert.Contains(t, mask.Values[5].Masks.Values, int32(1))
+		assert.Contains(t, mask.Values[5].Masks.Values[1].Masks.Values, int32(1))
+		assert.Contains(t, diffMask.Values[5].Masks.Values, int32(1))
+	})
+}
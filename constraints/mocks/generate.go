@@ -0,0 +1,5 @@
+//go:generate go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
+
+//go:generate protoc --go_out=./ --go_opt=paths=source_relative --proto_path=./ --proto_path=../proto validated.proto
+
+package mocks
@@ -0,0 +1,17 @@
+package mocks
+
+// CreateValidatedMessage returns a mock message that satisfies its buf.validate constraints
+func CreateValidatedMessage() *Validated {
+	return &Validated{
+		Name:   "validated",
+		Age:    30,
+		Nested: &ValidatedItem{Name: "nested"},
+		Items: []*ValidatedItem{
+			{Name: "item1"},
+			{Name: "item2"},
+		},
+		Map: map[string]*ValidatedItem{
+			"key1": {Name: "value1"},
+		},
+	}
+}
@@ -2,5 +2,6 @@
 //go:generate go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
 
 //go:generate protoc --go_out=./ --go_opt=paths=source_relative --proto_path=./ linearize_models.proto
+//go:generate protoc --go_out=./ --go_opt=module=github.com/fgrzl/linearize --proto_path=./proto linearize/options.proto
 
 package linearize
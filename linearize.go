@@ -1,117 +1,234 @@
 package linearize
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
-	"sort"
+	"slices"
+	"strings"
+	"sync"
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
-// Linearize recursively flattens a Protobuf message into a LinearizedObject.
-func Linearize(message proto.Message) (LinearizedObject, error) {
-	linearized := make(LinearizedObject)
+// ErrDepthExceeded is returned when messages are nested deeper than the configured maximum depth.
+var ErrDepthExceeded = errors.New("maximum depth exceeded")
 
-	// Return an empty LinearizedObject for nil message
-	if message == nil {
-		return linearized, nil
+// LinearizeOption configures Linearize.
+type LinearizeOption interface {
+	applyLinearize(*linearizeOptions)
+}
+
+type linearizeOptions struct {
+	filters     []*FieldFilter
+	maxDepth    int
+	mapKeyOrder MapKeyOrder
+	resolver    protoregistry.MessageTypeResolver
+	limiter     limiter
+}
+
+// linearizeOptionFunc adapts a function to a LinearizeOption.
+type linearizeOptionFunc func(*linearizeOptions)
+
+func (f linearizeOptionFunc) applyLinearize(o *linearizeOptions) {
+	f(o)
+}
+
+// MapKeyOrder determines the order, and therefore the positions, of map entries produced by Linearize.
+type MapKeyOrder int
+
+const (
+	// MapKeysLexical orders keys by their string form, so integer key 10 comes before 2.
+	MapKeysLexical MapKeyOrder = iota
+	// MapKeysNatural orders integer keys numerically, false before true and strings lexically.
+	MapKeysNatural
+)
+
+// MapKeyOrderOption sets the order of map entries. It implements both LinearizeOption and MergeOption,
+// where it tells MergeInto which order the map positions of a patch refer to.
+type MapKeyOrderOption MapKeyOrder
+
+// WithMapKeyOrder returns an option setting the order of map entries. Objects that are diffed or
+// merged together must be linearized with the same order.
+func WithMapKeyOrder(order MapKeyOrder) MapKeyOrderOption {
+	return MapKeyOrderOption(order)
+}
+
+func (o MapKeyOrderOption) applyLinearize(opts *linearizeOptions) {
+	opts.mapKeyOrder = MapKeyOrder(o)
+}
+
+func (o MapKeyOrderOption) applyMerge(opts *mergeOptions) {
+	opts.mapKeyOrder = MapKeyOrder(o)
+}
+
+// MaxDepthOption limits how deeply messages may be nested. The top-level message has depth 1.
+// It implements both LinearizeOption and DiffOption; exceeding the limit fails with ErrDepthExceeded.
+type MaxDepthOption int
+
+// WithMaxDepth returns an option limiting message nesting to depth levels, or no limit when depth is 0.
+func WithMaxDepth(depth int) MaxDepthOption {
+	return MaxDepthOption(depth)
+}
+
+func (o MaxDepthOption) applyLinearize(opts *linearizeOptions) {
+	opts.maxDepth = int(o)
+}
+
+func (o MaxDepthOption) applyDiff(opts *diffOptions) {
+	opts.maxDepth = int(o)
+}
+
+// Linearize recursively flattens a Protobuf message into a LinearizedObject. Fields marked
+// (linearize.field).diff_ignore are left out.
+func Linearize(message proto.Message, opts ...LinearizeOption) (LinearizedObject, error) {
+	if generated, ok := message.(Linearizer); ok && len(opts) == 0 {
+		return generated.Linearize()
 	}
+	return LinearizeContext(context.Background(), message, opts...)
+}
 
-	// Use reflection to inspect the message fields
-	msgReflect := message.ProtoReflect()
+// LinearizeContext flattens a message like Linearize, returning the context's error once it is done.
+// It always uses protoreflect, so WithLimits also bounds messages with generated methods.
+func LinearizeContext(ctx context.Context, message proto.Message, opts ...LinearizeOption) (LinearizedObject, error) {
+	options := linearizeOptions{limiter: limiter{ctx: ctx}, filters: descriptorFilters(message.ProtoReflect().Descriptor())}
+	for _, opt := range opts {
+		opt.applyLinearize(&options)
+	}
+	return options.linearize(message, newFieldScope(options.filters), 1)
+}
 
-	// Iterate over the fields of the message
-	msgReflect.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
-		key := int32(fd.Number())
+// less orders two map keys.
+func (order MapKeyOrder) less(a, b protoreflect.MapKey) bool {
+	if order == MapKeysNatural {
+		switch x := a.Interface().(type) {
+		case int32, int64:
+			return a.Int() < b.Int()
+		case uint32, uint64:
+			return a.Uint() < b.Uint()
+		case bool:
+			return !x && b.Bool()
+		}
+	}
+	return a.String() < b.String()
+}
 
-		// Handle map fields
-		if fd.IsMap() {
-			mapValue := make(LinearizedMap, 0)
+// mapEntry is a map entry being sorted. sortKey caches the string form of the key for lexical order.
+type mapEntry struct {
+	key     protoreflect.MapKey
+	value   protoreflect.Value
+	sortKey string
+}
 
-			// Collect keys to sort them lexicographically
-			type keyedValue struct {
-				Key   protoreflect.MapKey
-				Value protoreflect.Value
-			}
+// mapEntryPool reuses the buffers map entries are sorted in.
+var mapEntryPool = sync.Pool{
+	New: func() any { return new([]mapEntry) },
+}
 
-			var keys []keyedValue
-			value.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
-				keys = append(keys, keyedValue{Key: k, Value: v})
-				return true
+// sort orders map entries, formatting each key once when they are ordered by their string form.
+func (order MapKeyOrder) sort(entries []mapEntry) {
+	if len(entries) < 2 {
+		return
+	}
+	if order == MapKeysNatural {
+		switch entries[0].key.Interface().(type) {
+		case int32, int64:
+			slices.SortFunc(entries, func(a, b mapEntry) int {
+				return cmp.Compare(a.key.Int(), b.key.Int())
 			})
-
-			// Sort keys lexicographically
-			sort.SliceStable(keys, func(i, j int) bool {
-				return keys[i].Key.String() < keys[j].Key.String()
+			return
+		case uint32, uint64:
+			slices.SortFunc(entries, func(a, b mapEntry) int {
+				return cmp.Compare(a.key.Uint(), b.key.Uint())
 			})
-
-			// Process the sorted keys and their values
-			for _, kv := range keys {
-				mapKey := kv.Key.Interface()
-				mapVal := kv.Value
-
-				// Check if the map value is a message (i.e., needs linearization)
-				if mapVal.Message() != nil {
-					// Recursively linearize the nested message
-					nestedResult, err := Linearize(mapVal.Message().Interface().(proto.Message))
-					if err != nil {
-						return false
-					}
-					mapValue[int32(len(mapValue))] = [2]any{mapKey, nestedResult}
-				} else {
-					// Handle primitive types
-					mapValue[int32(len(mapValue))] = [2]any{mapKey, mapVal.Interface()}
+			return
+		case bool:
+			slices.SortFunc(entries, func(a, b mapEntry) int {
+				switch {
+				case order.less(a.key, b.key):
+					return -1
+				case order.less(b.key, a.key):
+					return 1
 				}
-			}
+				return 0
+			})
+			return
+		}
+	}
 
-			linearized[key] = mapValue
-		} else if fd.IsList() {
-			// Handle repeated fields (lists)
-			list := make(LinearizedSlice)
-
-			for i := 0; i < value.List().Len(); i++ {
-				elem := value.List().Get(i)
-
-				if fd.Kind() == protoreflect.MessageKind {
-					// Recursively linearize nested message elements
-					nestedMessage, ok := elem.Message().Interface().(proto.Message)
-					if !ok {
-						return false // Ensure type assertion succeeded
-					}
-
-					nestedResult, err := Linearize(nestedMessage)
-					if err != nil {
-						return false
-					}
-					list[int32(i)] = nestedResult // Use index as the key in LinearizedSlice
-				} else {
-					// Append primitive types directly
-					list[int32(i)] = elem.Interface() // Use index as the key in LinearizedSlice
-				}
-			}
-			linearized[key] = list
+	for i := range entries {
+		entries[i].sortKey = entries[i].key.String()
+	}
+	slices.SortFunc(entries, func(a, b mapEntry) int {
+		return strings.Compare(a.sortKey, b.sortKey)
+	})
+}
 
-		} else if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
-			// Recursively handle nested messages
-			nestedMessage := value.Message().Interface()
-			nestedResult, err := Linearize(nestedMessage.(proto.Message))
-			if err != nil {
-				return false
-			}
-			linearized[key] = nestedResult
-		} else {
-			// Handle primitive fields
-			linearized[key] = value.Interface()
+// linearize flattens a message at the given depth, keeping only the fields in scope.
+func (o *linearizeOptions) linearize(message proto.Message, scope fieldScope, depth int) (LinearizedObject, error) {
+	if o.maxDepth > 0 && depth > o.maxDepth {
+		return nil, fmt.Errorf("%w: %d", ErrDepthExceeded, o.maxDepth)
+	}
+	if err := o.limiter.enter(); err != nil {
+		return nil, err
+	}
+	// Return an empty LinearizedObject for nil message
+	if message == nil {
+		return make(LinearizedObject), nil
+	}
+
+	// Use reflection to inspect the message fields, sizing the object for all of them
+	msgReflect := message.ProtoReflect()
+	if packed, ok, err := o.unpackAny(msgReflect); err != nil {
+		return nil, err
+	} else if ok {
+		return o.linearizeAny(msgReflect, packed, scope, depth)
+	}
+	linearized := newObject(msgReflect.Descriptor().Fields().Len())
+
+	// Iterate over the fields of the message
+	var err error
+	msgReflect.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		key := int32(fd.Number())
+		nested, ok := scope.field(key)
+		if !ok {
+			return true
 		}
+		if err = o.limiter.add(1); err != nil {
+			return false
+		}
+
+		var linearizedValue any
+		linearizedValue, err = o.linearizeField(fd, value, nested, depth)
+		if err != nil {
+			return false
+		}
+		linearized[key] = linearizedValue
 		return true
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return linearized, nil
 }
 
 // Updated Unlinearize function
 func Unlinearize(m LinearizedObject, message proto.Message) error {
+	if generated, ok := message.(Unlinearizer); ok {
+		return generated.Unlinearize(m)
+	}
+
+	// Dynamic messages have no Go struct fields, so populate them through protoreflect
+	if dynamic, ok := message.(*dynamicpb.Message); ok {
+		return unlinearizeMessage(m, dynamic)
+	}
+
 	v := reflect.ValueOf(message)
 	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("result must be a pointer to a struct")
@@ -119,15 +236,25 @@ func Unlinearize(m LinearizedObject, message proto.Message) error {
 
 	msgReflect := message.ProtoReflect().Descriptor()
 	elem := v.Elem()
-	return unlinearizeStruct(elem, m, msgReflect)
+	return unlinearizeStruct(elem, m, msgReflect, nil)
+}
+
+// invalidValue reports a value Unlinearize cannot store as a *ValidationError at path.
+func invalidValue(path Path, format string, args ...any) error {
+	return &ValidationError{Violations: []Violation{{Path: path, Message: fmt.Sprintf(format, args...)}}}
 }
 
 // Recursive function to unlinearize structs
-func unlinearizeStruct(v reflect.Value, data LinearizedObject, msgReflect protoreflect.MessageDescriptor) error {
+func unlinearizeStruct(v reflect.Value, data LinearizedObject, msgReflect protoreflect.MessageDescriptor, path Path) error {
+	data, err := packAny(data, msgReflect)
+	if err != nil {
+		return err
+	}
 	for i, d := range data {
+		fieldPath := path.Field(i)
 		fd := msgReflect.Fields().ByNumber(protoreflect.FieldNumber(i))
 		if fd == nil {
-			return fmt.Errorf("field number %d not found in the message", i)
+			return invalidValue(fieldPath, "field number %d not found in the message", i)
 		}
 
 		fieldName := string(fd.Name())
@@ -141,47 +268,58 @@ func unlinearizeStruct(v reflect.Value, data LinearizedObject, msgReflect protor
 		}
 
 		switch value := d.(type) {
+		case nil:
+			// Removed fields are carried as nil, leave them unset
+			field.Set(reflect.Zero(field.Type()))
+
 		case LinearizedObject:
-			if field.Kind() == reflect.Ptr {
-				if field.IsNil() {
-					field.Set(reflect.New(field.Type().Elem()))
-				}
-				field = field.Elem()
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return invalidValue(fieldPath, "expected message for field %s but got object", fieldName)
 			}
-			if err := unlinearizeStruct(field, value, fd.Message()); err != nil {
-				return fmt.Errorf("failed to unlinearize nested field %s: %w", fieldName, err)
+			if err := unlinearizeValue(field, value, fd, fieldPath); err != nil {
+				return err
 			}
 
 		case LinearizedSlice:
-			if field.Kind() != reflect.Slice {
-				return fmt.Errorf("expected slice for field %s but got %s", fieldName, field.Kind())
+			if !fd.IsList() || field.Kind() != reflect.Slice {
+				return invalidValue(fieldPath, "expected slice for field %s but got %s", fieldName, field.Kind())
 			}
-			field.Set(reflect.MakeSlice(field.Type(), len(value), len(value)))
+			slice := reflect.MakeSlice(field.Type(), len(value), len(value))
 			for j, elem := range value {
-				elemValue := field.Index(int(j))
-				if err := unlinearizeValue(elemValue, elem, fd); err != nil {
-					return fmt.Errorf("failed to set slice element at index %d: %w", j, err)
+				if j < 0 || int(j) >= len(value) {
+					return invalidValue(fieldPath.Index(j), "index %d out of range for %d elements", j, len(value))
+				}
+				if err := unlinearizeValue(slice.Index(int(j)), elem, fd, fieldPath.Index(j)); err != nil {
+					return err
 				}
 			}
+			field.Set(slice)
 
 		case LinearizedMap:
-			if field.Kind() != reflect.Map {
-				return fmt.Errorf("expected map for field %s but got %s", fieldName, field.Kind())
+			if !fd.IsMap() || field.Kind() != reflect.Map {
+				return invalidValue(fieldPath, "expected map for field %s but got %s", fieldName, field.Kind())
 			}
-			field.Set(reflect.MakeMap(field.Type()))
-			for _, mapVal := range value {
-				kv := mapVal
-				key := reflect.ValueOf(kv[0]).Convert(field.Type().Key())
+			m := reflect.MakeMap(field.Type())
+			for _, kv := range value {
+				entryPath := fieldPath.Key(kv[0])
+				key, err := scalarValue(kv[0], fd.MapKey())
+				if err != nil {
+					return invalidValue(entryPath, "invalid map key: %v", err)
+				}
 				val := reflect.New(field.Type().Elem()).Elem()
-				if err := unlinearizeValue(val, kv[1], fd); err != nil {
-					return fmt.Errorf("failed to set map value for key %v: %w", key, err)
+				if err := unlinearizeValue(val, kv[1], fd.MapValue(), entryPath); err != nil {
+					return err
 				}
-				field.SetMapIndex(key, val)
+				m.SetMapIndex(reflect.ValueOf(key.Interface()).Convert(field.Type().Key()), val)
 			}
+			field.Set(m)
 
 		default:
-			if err := unlinearizeValue(field, value, fd); err != nil {
-				return fmt.Errorf("failed to set field %s: %w", fieldName, err)
+			if fd.IsList() || fd.IsMap() {
+				return invalidValue(fieldPath, "expected composite value for field %s but got %T", fieldName, d)
+			}
+			if err := unlinearizeValue(field, value, fd, fieldPath); err != nil {
+				return err
 			}
 		}
 	}
@@ -189,9 +327,14 @@ func unlinearizeStruct(v reflect.Value, data LinearizedObject, msgReflect protor
 }
 
 // Helper to unlinearize a single value
-func unlinearizeValue(field reflect.Value, value any, fd protoreflect.FieldDescriptor) error {
+func unlinearizeValue(field reflect.Value, value any, fd protoreflect.FieldDescriptor, path Path) error {
 	switch fd.Kind() {
-	case protoreflect.MessageKind:
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		obj, ok := value.(LinearizedObject)
+		if !ok {
+			return invalidValue(path, "expected object for message %s but got %T", fd.Message().FullName(), value)
+		}
+
 		// Ensure the field is settable and is a pointer
 		if field.Kind() != reflect.Ptr {
 			return fmt.Errorf("expected pointer to a message, but got %s", field.Kind())
@@ -203,16 +346,226 @@ func unlinearizeValue(field reflect.Value, value any, fd protoreflect.FieldDescr
 		}
 
 		// Recursively unlinearize the nested message
-		nestedMessage := field.Interface().(proto.Message)
-		return Unlinearize(value.(LinearizedObject), nestedMessage)
+		if generated, ok := field.Interface().(Unlinearizer); ok {
+			return generated.Unlinearize(obj)
+		}
+		return unlinearizeStruct(field.Elem(), obj, fd.Message(), path)
 
 	default:
 		// Handle primitive fields
+		if value == nil {
+			return invalidValue(path, "expected %s but got nil", fd.Kind())
+		}
 		actualValue := reflect.ValueOf(value)
 		if !actualValue.Type().AssignableTo(field.Type()) {
-			return fmt.Errorf("type mismatch: expected %s but got %s", field.Type(), actualValue.Type())
+			return invalidValue(path, "type mismatch: expected %s but got %s", field.Type(), actualValue.Type())
 		}
 		field.Set(actualValue)
 	}
 	return nil
 }
+
+// unlinearizeMessage populates a message through protoreflect
+func unlinearizeMessage(data LinearizedObject, message protoreflect.Message) error {
+	data, err := packAny(data, message.Descriptor())
+	if err != nil {
+		return err
+	}
+	fields := message.Descriptor().Fields()
+	for i, d := range data {
+		fd := fields.ByNumber(protoreflect.FieldNumber(i))
+		if fd == nil {
+			return fmt.Errorf("field number %d not found in the message", i)
+		}
+		fieldName := string(fd.Name())
+
+		switch value := d.(type) {
+		case nil:
+			message.Clear(fd)
+
+		case LinearizedObject:
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("expected message for field %s but got object", fieldName)
+			}
+			message.Clear(fd)
+			if err := unlinearizeMessage(value, message.Mutable(fd).Message()); err != nil {
+				return fmt.Errorf("failed to unlinearize nested field %s: %w", fieldName, err)
+			}
+
+		case LinearizedSlice:
+			if !fd.IsList() {
+				return fmt.Errorf("expected slice for field %s", fieldName)
+			}
+			message.Clear(fd)
+			list := message.Mutable(fd).List()
+			for j := 0; j < len(value); j++ {
+				elem, err := unlinearizeElement(list.NewElement(), value[int32(j)], fd)
+				if err != nil {
+					return fmt.Errorf("failed to set slice element at index %d: %w", j, err)
+				}
+				list.Append(elem)
+			}
+
+		case LinearizedMap:
+			if !fd.IsMap() {
+				return fmt.Errorf("expected map for field %s", fieldName)
+			}
+			message.Clear(fd)
+			m := message.Mutable(fd).Map()
+			for _, kv := range value {
+				key, err := scalarValue(kv[0], fd.MapKey())
+				if err != nil {
+					return fmt.Errorf("failed to set map key %v: %w", kv[0], err)
+				}
+				val, err := unlinearizeElement(m.NewValue(), kv[1], fd.MapValue())
+				if err != nil {
+					return fmt.Errorf("failed to set map value for key %v: %w", kv[0], err)
+				}
+				m.Set(key.MapKey(), val)
+			}
+
+		default:
+			if fd.IsList() || fd.IsMap() || fd.Message() != nil {
+				return fmt.Errorf("expected composite value for field %s but got %T", fieldName, d)
+			}
+			val, err := scalarValue(value, fd)
+			if err != nil {
+				return fmt.Errorf("failed to set field %s: %w", fieldName, err)
+			}
+			message.Set(fd, val)
+		}
+	}
+	return nil
+}
+
+// unlinearizeElement converts a list element or map value, populating elem for messages
+func unlinearizeElement(elem protoreflect.Value, value any, fd protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	if fd.Message() == nil {
+		return scalarValue(value, fd)
+	}
+
+	obj, ok := value.(LinearizedObject)
+	if !ok {
+		return protoreflect.Value{}, fmt.Errorf("expected object but got %T", value)
+	}
+	if err := unlinearizeMessage(obj, elem.Message()); err != nil {
+		return protoreflect.Value{}, err
+	}
+	return elem, nil
+}
+
+// scalarValue converts a linearized scalar to a protoreflect value of the field's kind
+func scalarValue(value any, fd protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	var ok bool
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		_, ok = value.(bool)
+	case protoreflect.EnumKind:
+		if n, isInt := value.(int32); isInt {
+			value, ok = protoreflect.EnumNumber(n), true
+		} else {
+			_, ok = value.(protoreflect.EnumNumber)
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		_, ok = value.(int32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		_, ok = value.(int64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		_, ok = value.(uint32)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		_, ok = value.(uint64)
+	case protoreflect.FloatKind:
+		_, ok = value.(float32)
+	case protoreflect.DoubleKind:
+		_, ok = value.(float64)
+	case protoreflect.StringKind:
+		_, ok = value.(string)
+	case protoreflect.BytesKind:
+		_, ok = value.([]byte)
+	}
+	if !ok {
+		return protoreflect.Value{}, fmt.Errorf("type mismatch: expected %s but got %T", fd.Kind(), value)
+	}
+	return protoreflect.ValueOf(value), nil
+}
+
+// linearizeField flattens the value of a populated field.
+func (o *linearizeOptions) linearizeField(fd protoreflect.FieldDescriptor, value protoreflect.Value, nested fieldScope, depth int) (any, error) {
+	// Handle map fields
+	if fd.IsMap() {
+		m := value.Map()
+		if err := o.limiter.mapping(m.Len()); err != nil {
+			return nil, err
+		}
+		mapValue := newMap(m.Len())
+
+		// Collect entries into a pooled buffer to sort them
+		buffer := mapEntryPool.Get().(*[]mapEntry)
+		entries := (*buffer)[:0]
+		m.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries = append(entries, mapEntry{key: k, value: v})
+			return true
+		})
+		defer func() {
+			clear(entries)
+			*buffer = entries[:0]
+			mapEntryPool.Put(buffer)
+		}()
+
+		// Sort keys in the configured order
+		o.mapKeyOrder.sort(entries)
+
+		// Process the sorted keys and their values
+		for _, entry := range entries {
+			mapKey := entry.key.Interface()
+			mapVal := entry.value
+
+			// Check if the map value is a message (i.e., needs linearization)
+			if fd.MapValue().Message() != nil {
+				// Recursively linearize the nested message
+				nestedResult, err := o.linearize(mapVal.Message().Interface(), nested, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				mapValue[int32(len(mapValue))] = [2]any{mapKey, nestedResult}
+			} else {
+				// Handle primitive types
+				mapValue[int32(len(mapValue))] = [2]any{mapKey, mapVal.Interface()}
+			}
+		}
+		return mapValue, nil
+	}
+
+	if fd.IsList() {
+		// Handle repeated fields (lists)
+		if err := o.limiter.list(value.List().Len()); err != nil {
+			return nil, err
+		}
+		list := newSlice(value.List().Len())
+
+		for i := 0; i < value.List().Len(); i++ {
+			elem := value.List().Get(i)
+
+			if fd.Kind() == protoreflect.MessageKind {
+				// Recursively linearize nested message elements
+				nestedResult, err := o.linearize(elem.Message().Interface(), nested, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				list[int32(i)] = nestedResult // Use index as the key in LinearizedSlice
+			} else {
+				// Append primitive types directly
+				list[int32(i)] = elem.Interface() // Use index as the key in LinearizedSlice
+			}
+		}
+		return list, nil
+	}
+
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		// Recursively handle nested messages
+		return o.linearize(value.Message().Interface(), nested, depth+1)
+	}
+
+	// Handle primitive fields
+	return value.Interface(), nil
+}
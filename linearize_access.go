@@ -0,0 +1,446 @@
+package linearize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	// ErrPathNotFound is returned when a path does not exist in the object
+	ErrPathNotFound = errors.New("path not found")
+	// ErrPathType is returned when a path step does not match the value it is applied to
+	ErrPathType = errors.New("path does not match value type")
+	// ErrInvalidPath is returned when a path cannot be parsed or used for the operation
+	ErrInvalidPath = errors.New("invalid path")
+	// SkipPath is returned by a Walk callback to skip the children of the current value
+	SkipPath = errors.New("skip path")
+)
+
+// PathError records the operation and path that failed.
+type PathError struct {
+	Op   string
+	Path Path
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// PathElementKind identifies what a PathElement addresses.
+type PathElementKind int
+
+const (
+	FieldElement PathElementKind = iota // field of a LinearizedObject
+	IndexElement                        // element of a LinearizedSlice
+	KeyElement                          // entry of a LinearizedMap
+)
+
+// PathElement is a single step of a Path.
+type PathElement struct {
+	Kind  PathElementKind
+	Field int32 // Field number for FieldElement
+	Index int32 // Index for IndexElement
+	Key   any   // Map key for KeyElement
+}
+
+// Path addresses a value inside a LinearizedObject by field numbers, slice indices and map keys.
+type Path []PathElement
+
+// Field returns a copy of the path extended with a field number.
+func (p Path) Field(number int32) Path {
+	return append(p[:len(p):len(p)], PathElement{Kind: FieldElement, Field: number})
+}
+
+// Index returns a copy of the path extended with a slice index.
+func (p Path) Index(index int32) Path {
+	return append(p[:len(p):len(p)], PathElement{Kind: IndexElement, Index: index})
+}
+
+// Key returns a copy of the path extended with a map key.
+func (p Path) Key(key any) Path {
+	return append(p[:len(p):len(p)], PathElement{Kind: KeyElement, Key: key})
+}
+
+// String renders the path with field numbers, for example 4[1].2 or 5["key"].1.
+func (p Path) String() string {
+	return p.format(nil)
+}
+
+// Format renders the path with proto field names from the descriptor, in the format accepted by ParsePath.
+func (p Path) Format(md protoreflect.MessageDescriptor) string {
+	return p.format(md)
+}
+
+func (p Path) format(md protoreflect.MessageDescriptor) string {
+	var sb strings.Builder
+	for _, elem := range p {
+		switch elem.Kind {
+		case FieldElement:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			var fd protoreflect.FieldDescriptor
+			if md != nil {
+				fd = md.Fields().ByNumber(protoreflect.FieldNumber(elem.Field))
+			}
+			if fd == nil {
+				sb.WriteString(strconv.Itoa(int(elem.Field)))
+				md = nil
+				continue
+			}
+			sb.WriteString(string(fd.Name()))
+			md = fd.Message()
+			if fd.IsMap() {
+				md = fd.MapValue().Message()
+			}
+		case IndexElement:
+			fmt.Fprintf(&sb, "[%d]", elem.Index)
+		case KeyElement:
+			sb.WriteString("[" + formatMapKey(elem.Key) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// ParsePath parses a name-based path such as Nested.Repeated[1] or Map["key"].Field1,
+// converting map keys to the key type declared by the descriptor.
+func ParsePath(s string, md protoreflect.MessageDescriptor) (Path, error) {
+	tokens, err := parsePathTokens(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	var path Path
+	var fd protoreflect.FieldDescriptor
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.kind != pathTokenField {
+			return nil, fmt.Errorf("%w: %q: unexpected %s", ErrInvalidPath, s, token)
+		}
+		if md == nil {
+			return nil, fmt.Errorf("%w: %q: %s has no fields", ErrInvalidPath, s, path)
+		}
+
+		if fd, err = lookupField(md, token.name); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidPath, s, err)
+		}
+		path = path.Field(int32(fd.Number()))
+		md = fd.Message()
+		if fd.IsList() || fd.IsMap() {
+			// Fields of elements and entries are only reachable through an index or key
+			md = nil
+		}
+
+		if i+1 < len(tokens) && tokens[i+1].kind != pathTokenField {
+			i++
+			switch {
+			case fd.IsList() && tokens[i].kind == pathTokenIndex:
+				if tokens[i].index < 0 || tokens[i].index > math.MaxInt32 {
+					return nil, fmt.Errorf("%w: %q: index %d out of range", ErrInvalidPath, s, tokens[i].index)
+				}
+				path = path.Index(int32(tokens[i].index))
+				md = fd.Message()
+			case fd.IsMap():
+				key, err := parseMapKey(tokens[i], fd.MapKey())
+				if err != nil {
+					return nil, fmt.Errorf("%w: %q: %v", ErrInvalidPath, s, err)
+				}
+				path = path.Key(key)
+				md = fd.MapValue().Message()
+			default:
+				return nil, fmt.Errorf("%w: %q: field %s cannot be addressed by %s", ErrInvalidPath, s, fd.Name(), tokens[i])
+			}
+		}
+	}
+	return path, nil
+}
+
+// Get returns the value at the path.
+func (o LinearizedObject) Get(path Path) (any, error) {
+	var current any = o
+	for i, elem := range path {
+		next, exists, err := child(current, elem)
+		if err != nil {
+			return nil, &PathError{Op: "get", Path: path[:i+1], Err: err}
+		}
+		if !exists {
+			return nil, &PathError{Op: "get", Path: path[:i+1], Err: ErrPathNotFound}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// Set stores the value at the path, creating missing objects, slices and maps along the way.
+// Slice elements may be replaced or appended at the end of the slice. New map entries are inserted
+// before the first key that sorts after them in the default key order, without reordering the others.
+func (o LinearizedObject) Set(path Path, value any) error {
+	if len(path) == 0 {
+		return &PathError{Op: "set", Path: path, Err: ErrInvalidPath}
+	}
+
+	var current any = o
+	for i, elem := range path[:len(path)-1] {
+		next, exists, err := child(current, elem)
+		if err != nil {
+			return &PathError{Op: "set", Path: path[:i+1], Err: err}
+		}
+		if !exists || next == nil {
+			next = containerFor(path[i+1])
+			if err := setChild(current, elem, next); err != nil {
+				return &PathError{Op: "set", Path: path[:i+1], Err: err}
+			}
+		}
+		current = next
+	}
+
+	if err := setChild(current, path[len(path)-1], value); err != nil {
+		return &PathError{Op: "set", Path: path, Err: err}
+	}
+	return nil
+}
+
+// Delete removes the value at the path. Later slice elements and map entries move down
+// so that indices and positions stay contiguous.
+func (o LinearizedObject) Delete(path Path) error {
+	if len(path) == 0 {
+		return &PathError{Op: "delete", Path: path, Err: ErrInvalidPath}
+	}
+
+	parent, err := o.Get(path[:len(path)-1])
+	if err != nil {
+		pathErr := err.(*PathError)
+		pathErr.Op = "delete"
+		return pathErr
+	}
+
+	last := path[len(path)-1]
+	if _, exists, err := child(parent, last); err != nil || !exists {
+		if err == nil {
+			err = ErrPathNotFound
+		}
+		return &PathError{Op: "delete", Path: path, Err: err}
+	}
+
+	switch container := parent.(type) {
+	case LinearizedObject:
+		delete(container, last.Field)
+	case LinearizedSlice:
+		for i := last.Index; i < int32(len(container))-1; i++ {
+			container[i] = container[i+1]
+		}
+		delete(container, int32(len(container))-1)
+	case LinearizedMap:
+		position := mapPosition(container, last.Key)
+		for i := position; i < int32(len(container))-1; i++ {
+			container[i] = container[i+1]
+		}
+		delete(container, int32(len(container))-1)
+	}
+	return nil
+}
+
+// Walk calls fn for every value in the object in field, index and map position order,
+// visiting containers before their children. Returning SkipPath from fn skips the children
+// of the current value; any other error stops the walk and is returned.
+func (o LinearizedObject) Walk(fn func(path Path, value any) error) error {
+	err := walkChildren(nil, o, fn)
+	if errors.Is(err, SkipPath) {
+		return nil
+	}
+	return err
+}
+
+func walkValue(path Path, value any, fn func(path Path, value any) error) error {
+	if err := fn(path, value); err != nil {
+		if errors.Is(err, SkipPath) {
+			return nil
+		}
+		return err
+	}
+	return walkChildren(path, value, fn)
+}
+
+func walkChildren(path Path, value any, fn func(path Path, value any) error) error {
+	switch container := value.(type) {
+	case LinearizedObject:
+		for _, key := range sortedKeys(container) {
+			if err := walkValue(path.Field(key), container[key], fn); err != nil {
+				return err
+			}
+		}
+	case LinearizedSlice:
+		for _, index := range sortedKeys(container) {
+			if err := walkValue(path.Index(index), container[index], fn); err != nil {
+				return err
+			}
+		}
+	case LinearizedMap:
+		for _, position := range sortedKeys(container) {
+			entry := container[position]
+			if err := walkValue(path.Key(entry[0]), entry[1], fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// child returns the value addressed by elem inside container.
+func child(container any, elem PathElement) (any, bool, error) {
+	switch c := container.(type) {
+	case LinearizedObject:
+		if elem.Kind == FieldElement {
+			value, exists := c[elem.Field]
+			return value, exists, nil
+		}
+	case LinearizedSlice:
+		if elem.Kind == IndexElement {
+			value, exists := c[elem.Index]
+			return value, exists, nil
+		}
+	case LinearizedMap:
+		if elem.Kind == KeyElement {
+			if position := mapPosition(c, elem.Key); position >= 0 {
+				return c[position][1], true, nil
+			}
+			return nil, false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("%w: cannot apply %s to %T", ErrPathType, Path{elem}, container)
+}
+
+// setChild stores value at elem inside container.
+func setChild(container any, elem PathElement, value any) error {
+	switch c := container.(type) {
+	case LinearizedObject:
+		if elem.Kind == FieldElement {
+			c[elem.Field] = value
+			return nil
+		}
+	case LinearizedSlice:
+		if elem.Kind == IndexElement {
+			if elem.Index < 0 || elem.Index > int32(len(c)) {
+				return fmt.Errorf("%w: index %d out of range", ErrPathNotFound, elem.Index)
+			}
+			c[elem.Index] = value
+			return nil
+		}
+	case LinearizedMap:
+		if elem.Kind == KeyElement {
+			if position := mapPosition(c, elem.Key); position >= 0 {
+				c[position] = [2]any{c[position][0], value}
+				return nil
+			}
+			insertMapEntry(c, [2]any{elem.Key, value})
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot apply %s to %T", ErrPathType, Path{elem}, container)
+}
+
+// containerFor returns an empty container that can be addressed by elem.
+func containerFor(elem PathElement) any {
+	switch elem.Kind {
+	case IndexElement:
+		return make(LinearizedSlice)
+	case KeyElement:
+		return make(LinearizedMap)
+	}
+	return make(LinearizedObject)
+}
+
+// mapPosition returns the position of key in the map, or -1 if it is not present.
+func mapPosition(m LinearizedMap, key any) int32 {
+	for position, entry := range m {
+		if mapKeysEqual(entry[0], key) {
+			return position
+		}
+	}
+	return -1
+}
+
+// mapKeysEqual compares map keys, treating integers of different widths as equal.
+func mapKeysEqual(a, b any) bool {
+	if a == b {
+		return true
+	}
+	ai, aok := integerKey(a)
+	bi, bok := integerKey(b)
+	return aok && bok && ai == bi
+}
+
+func integerKey(key any) (string, bool) {
+	switch key.(type) {
+	case int, int32, int64, uint, uint32, uint64:
+		return fmt.Sprint(key), true
+	}
+	return "", false
+}
+
+// insertMapEntry inserts an entry before the first entry whose key sorts after it in the default key
+// order, moving the later entries up. The other entries keep their order.
+func insertMapEntry(m LinearizedMap, entry [2]any) {
+	position := int32(len(m))
+	for p := int32(0); p < int32(len(m)); p++ {
+		if MapKeysLexical.lessValue(entry[0], m[p][0]) {
+			position = p
+			break
+		}
+	}
+	for p := int32(len(m)); p > position; p-- {
+		m[p] = m[p-1]
+	}
+	m[position] = entry
+}
+
+// sortMapEntries renumbers the entries of a map in the key order used by Linearize with order.
+func sortMapEntries(m LinearizedMap, order MapKeyOrder) {
+	entries := make([][2]any, 0, len(m))
+	for _, position := range sortedKeys(m) {
+		entries = append(entries, m[position])
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return order.lessValue(entries[i][0], entries[j][0])
+	})
+	for i, entry := range entries {
+		m[int32(i)] = entry
+	}
+}
+
+// parseMapKey converts a path token to a map key of the declared kind.
+func parseMapKey(token pathToken, fd protoreflect.FieldDescriptor) (any, error) {
+	text := token.name
+	if token.kind == pathTokenIndex {
+		text = strconv.FormatInt(token.index, 10)
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return text, nil
+	case protoreflect.BoolKind:
+		return strconv.ParseBool(text)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(text, 10, 32)
+		return int32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.ParseInt(text, 10, 64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(text, 10, 32)
+		return uint32(v), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.ParseUint(text, 10, 64)
+	}
+	return nil, fmt.Errorf("unsupported map key kind %s", fd.Kind())
+}
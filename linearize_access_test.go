@@ -0,0 +1,167 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAccess(t *testing.T) {
+	md := (&mocks.SuperComplex{}).ProtoReflect().Descriptor()
+
+	linearizeSuperComplex := func(t *testing.T) LinearizedObject {
+		linearized, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		return linearized
+	}
+
+	t.Run("should get value by path", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+
+		// Act
+		value, err := linearized.Get(Path{}.Field(3).Field(4).Index(0).Field(1))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "complex_repeated_field1", value)
+	})
+
+	t.Run("should get value by parsed path", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+		path, err := ParsePath(`Map[2].Nested.Field1`, md)
+		require.NoError(t, err)
+
+		// Act
+		value, err := linearized.Get(path)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "map_nested_field2", value)
+		assert.Equal(t, `Map[2].Nested.Field1`, path.Format(md))
+		assert.Equal(t, `5[2].3.1`, path.String())
+	})
+
+	t.Run("should return typed errors", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+
+		// Act
+		_, notFound := linearized.Get(Path{}.Field(3).Field(4).Index(5))
+		_, wrongType := linearized.Get(Path{}.Field(1).Field(2))
+		_, invalid := ParsePath("Nested.Missing", md)
+
+		// Assert
+		assert.ErrorIs(t, notFound, ErrPathNotFound)
+		assert.ErrorIs(t, wrongType, ErrPathType)
+		assert.ErrorIs(t, invalid, ErrInvalidPath)
+
+		var pathErr *PathError
+		require.ErrorAs(t, notFound, &pathErr)
+		assert.Equal(t, "get", pathErr.Op)
+		assert.Equal(t, "3.4[5]", pathErr.Path.String())
+	})
+
+	t.Run("should set values and create missing containers", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+		mapPath, err := ParsePath(`Nested.Map["key0"].Field1`, md)
+		require.NoError(t, err)
+
+		// Act
+		require.NoError(t, linearized.Set(Path{}.Field(1), "changed"))
+		require.NoError(t, linearized.Set(Path{}.Field(4).Index(1).Field(1), "appended"))
+		require.NoError(t, linearized.Set(mapPath, "inserted"))
+
+		// Assert
+		var msg mocks.SuperComplex
+		require.NoError(t, Unlinearize(linearized, &msg))
+		assert.Equal(t, "changed", msg.Field1)
+		require.Len(t, msg.Repeated, 2)
+		assert.Equal(t, "appended", msg.Repeated[1].Field1)
+		assert.Equal(t, "inserted", msg.Nested.Map["key0"].Field1)
+
+		// Map entries stay in key order
+		assert.Equal(t, "key0", linearized[3].(LinearizedObject)[5].(LinearizedMap)[0][0])
+	})
+
+	t.Run("should insert map entries without reordering the others", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(&mocks.SuperComplex{Map: map[int32]*mocks.Complex{
+			2:  {Field1: "two"},
+			10: {Field1: "ten"},
+		}}, WithMapKeyOrder(MapKeysNatural))
+		require.NoError(t, err)
+
+		// Act
+		err = linearized.Set(Path{}.Field(5).Key(int32(20)), LinearizedObject{1: "twenty"})
+
+		// Assert
+		require.NoError(t, err)
+		entries := linearized[5].(LinearizedMap)
+		assert.Equal(t, []any{int32(2), int32(10), int32(20)}, []any{entries[0][0], entries[1][0], entries[2][0]})
+	})
+
+	t.Run("should reject setting slice elements past the end", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+
+		// Act
+		err := linearized.Set(Path{}.Field(4).Index(3), LinearizedObject{})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrPathNotFound)
+	})
+
+	t.Run("should delete values and keep slices contiguous", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+
+		// Act
+		require.NoError(t, linearized.Delete(Path{}.Field(3).Field(3).Field(3).Index(0)))
+		require.NoError(t, linearized.Delete(Path{}.Field(3).Field(5).Key("key1")))
+		require.NoError(t, linearized.Delete(Path{}.Field(2)))
+		err := linearized.Delete(Path{}.Field(2))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrPathNotFound)
+
+		var msg mocks.SuperComplex
+		require.NoError(t, Unlinearize(linearized, &msg))
+		expected := mocks.CreateSuperComplexMessage()
+		expected.Field2 = 0
+		expected.Nested.Nested.Repeated = expected.Nested.Nested.Repeated[1:]
+		delete(expected.Nested.Map, "key1")
+		assert.True(t, proto.Equal(expected, &msg))
+	})
+
+	t.Run("should walk values in order", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		// Act
+		var paths []string
+		err = linearized.Walk(func(path Path, value any) error {
+			paths = append(paths, path.String())
+			if len(path) == 1 && path[0].Field == 4 {
+				return SkipPath
+			}
+			return nil
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"1", "2",
+			"3", "3.1", "3.2", "3.3", "3.3[0]", "3.3[1]",
+			"4",
+			"5", `5["key1"]`, `5["key1"].1`, `5["key1"].2`, `5["key1"].3`, `5["key1"].3[0]`, `5["key1"].3[1]`,
+			`5["key2"]`, `5["key2"].1`, `5["key2"].2`, `5["key2"].3`, `5["key2"].3[0]`, `5["key2"].3[1]`,
+		}, paths)
+	})
+}
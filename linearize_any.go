@@ -0,0 +1,150 @@
+package linearize
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// anyFullName is the name of google.protobuf.Any, whose value field holds another message.
+const anyFullName protoreflect.FullName = "google.protobuf.Any"
+
+// Field numbers of google.protobuf.Any.
+const (
+	anyTypeURL int32 = 1
+	anyValue   int32 = 2
+)
+
+// WithResolver makes Linearize expand google.protobuf.Any values whose type the resolver knows:
+// the value field holds the linearized message instead of its encoding, so Diff reports changes
+// inside it. Unknown types stay packed. Unlinearize and Validate resolve expanded values through
+// protoregistry.GlobalTypes.
+func WithResolver(resolver protoregistry.MessageTypeResolver) LinearizeOption {
+	return linearizeOptionFunc(func(o *linearizeOptions) {
+		o.resolver = resolver
+	})
+}
+
+// unpackAny returns the message packed in a google.protobuf.Any, or false when no resolver is set,
+// the message is not an Any or the resolver does not know its type.
+func (o *linearizeOptions) unpackAny(message protoreflect.Message) (proto.Message, bool, error) {
+	if o.resolver == nil || message.Descriptor().FullName() != anyFullName {
+		return nil, false, nil
+	}
+	fields := message.Descriptor().Fields()
+	typeURL := message.Get(fields.ByNumber(protoreflect.FieldNumber(anyTypeURL))).String()
+	mt, err := o.resolver.FindMessageByURL(typeURL)
+	if errors.Is(err, protoregistry.NotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to resolve %s: %w", typeURL, err)
+	}
+
+	packed := mt.New().Interface()
+	if err := proto.Unmarshal(message.Get(fields.ByNumber(protoreflect.FieldNumber(anyValue))).Bytes(), packed); err != nil {
+		return nil, false, fmt.Errorf("failed to unpack %s: %w", typeURL, err)
+	}
+	return packed, true, nil
+}
+
+// linearizeAny flattens an expanded google.protobuf.Any, keeping only the fields in scope.
+func (o *linearizeOptions) linearizeAny(message protoreflect.Message, packed proto.Message, scope fieldScope, depth int) (LinearizedObject, error) {
+	typeURL := message.Get(message.Descriptor().Fields().ByNumber(protoreflect.FieldNumber(anyTypeURL))).String()
+	linearized := newObject(2)
+	if _, ok := scope.field(anyTypeURL); ok {
+		if err := o.limiter.add(1); err != nil {
+			return nil, err
+		}
+		linearized[anyTypeURL] = typeURL
+	}
+	if nested, ok := scope.field(anyValue); ok {
+		if err := o.limiter.add(1); err != nil {
+			return nil, err
+		}
+		value, err := o.linearize(packed, nested, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		linearized[anyValue] = value
+	}
+	return linearized, nil
+}
+
+// emitAny streams an expanded google.protobuf.Any like linearizeAny flattens it.
+func (o *linearizeOptions) emitAny(field int32, message protoreflect.Message, packed proto.Message, scope fieldScope, depth int, e Emitter) error {
+	typeURL := message.Get(message.Descriptor().Fields().ByNumber(protoreflect.FieldNumber(anyTypeURL))).String()
+	_, hasURL := scope.field(anyTypeURL)
+	nested, hasValue := scope.field(anyValue)
+	size := 0
+	if hasURL {
+		size++
+	}
+	if hasValue {
+		size++
+	}
+
+	if err := e.BeginObject(field, size); err != nil {
+		return err
+	}
+	if hasURL {
+		if err := o.limiter.add(1); err != nil {
+			return err
+		}
+		if err := e.Scalar(anyTypeURL, typeURL); err != nil {
+			return err
+		}
+	}
+	if hasValue {
+		if err := o.limiter.add(1); err != nil {
+			return err
+		}
+		if err := o.emit(anyValue, packed, nested, depth+1, e); err != nil {
+			return err
+		}
+	}
+	return e.End()
+}
+
+// packAny returns the fields of a google.protobuf.Any with an expanded value encoded again, resolving
+// its type through protoregistry.GlobalTypes. Other objects are returned as they are.
+func packAny(data LinearizedObject, md protoreflect.MessageDescriptor) (LinearizedObject, error) {
+	obj, ok := data[anyValue].(LinearizedObject)
+	if md.FullName() != anyFullName || !ok {
+		return data, nil
+	}
+	packed, err := resolveAny(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := unlinearizeMessage(obj, packed.ProtoReflect()); err != nil {
+		return nil, fmt.Errorf("failed to unlinearize %s: %w", data[anyTypeURL], err)
+	}
+	value, err := proto.MarshalOptions{Deterministic: true}.Marshal(packed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", data[anyTypeURL], err)
+	}
+
+	result := make(LinearizedObject, len(data))
+	for key, v := range data {
+		result[key] = v
+	}
+	result[anyValue] = value
+	return result, nil
+}
+
+// resolveAny returns an empty message of the type named by the type URL of an expanded Any.
+func resolveAny(data LinearizedObject) (proto.Message, error) {
+	typeURL, ok := data[anyTypeURL].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected type URL for expanded Any but got %T", data[anyTypeURL])
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", typeURL, err)
+	}
+	return mt.New().Interface(), nil
+}
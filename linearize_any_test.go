@@ -0,0 +1,99 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestWithResolver(t *testing.T) {
+	option := func(t *testing.T, simple *mocks.Simple) *typepb.Option {
+		value, err := anypb.New(simple)
+		require.NoError(t, err)
+		return &typepb.Option{Name: "option", Value: value}
+	}
+	resolver := WithResolver(protoregistry.GlobalTypes)
+
+	t.Run("should expand Any values of known types", func(t *testing.T) {
+		// Arrange
+		msg := option(t, mocks.CreateSimpleMessage())
+		expected, err := Linearize(mocks.CreateSimpleMessage())
+		require.NoError(t, err)
+
+		// Act
+		obj, err := Linearize(msg, resolver)
+
+		// Assert
+		require.NoError(t, err)
+		value := obj[2].(LinearizedObject)
+		assert.Equal(t, msg.Value.TypeUrl, value[1])
+		assert.Equal(t, expected, value[2])
+		assert.NoError(t, Validate(obj, msg.ProtoReflect().Descriptor()))
+	})
+
+	t.Run("should pack expanded values when unlinearizing", func(t *testing.T) {
+		// Arrange
+		msg := option(t, mocks.CreateSimpleMessage())
+		obj, err := Linearize(msg, resolver)
+		require.NoError(t, err)
+		actual := dynamicpb.NewMessage(msg.ProtoReflect().Descriptor())
+
+		// Act
+		err = Unlinearize(obj, actual)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(msg, actual), "got %v", actual)
+	})
+
+	t.Run("should diff fields inside Any values", func(t *testing.T) {
+		// Arrange
+		changed := mocks.CreateSimpleMessage()
+		changed.Field2 = 7
+		previous, err := Linearize(option(t, mocks.CreateSimpleMessage()), resolver)
+		require.NoError(t, err)
+		latest, err := Linearize(option(t, changed), resolver)
+		require.NoError(t, err)
+
+		// Act
+		_, _, mask, err := Diff(previous, latest)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int32{2}, sortedMaskKeys(mask.Values[2].Masks.Values[2].Masks))
+	})
+
+	t.Run("should stream the same object", func(t *testing.T) {
+		// Arrange
+		msg := option(t, mocks.CreateSimpleMessage())
+		expected, err := Linearize(msg, resolver)
+		require.NoError(t, err)
+		emitter := NewObjectEmitter()
+
+		// Act
+		err = LinearizeTo(msg, emitter, resolver)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, emitter.Object())
+	})
+
+	t.Run("should keep Any values of unknown types packed", func(t *testing.T) {
+		// Arrange
+		msg := option(t, mocks.CreateSimpleMessage())
+
+		// Act
+		obj, err := Linearize(msg, WithResolver(new(protoregistry.Types)))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, msg.Value.Value, obj[2].(LinearizedObject)[2])
+	})
+}
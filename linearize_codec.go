@@ -0,0 +1,353 @@
+package linearize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrInvalidEncoding is returned when decoding data that was not produced by the codec.
+var ErrInvalidEncoding = errors.New("invalid linearized encoding")
+
+// Type tags written in front of every encoded value
+const (
+	tagNil byte = iota
+	tagObject
+	tagSlice
+	tagMap
+	tagBool
+	tagInt32
+	tagInt64
+	tagUint32
+	tagUint64
+	tagFloat32
+	tagFloat64
+	tagString
+	tagBytes
+	tagEnum
+	tagRedacted
+)
+
+// maxEncodingDepth bounds how deeply encoded objects, slices and maps may be nested, so crafted
+// input cannot exhaust the stack. It matches the default recursion limit of proto.Unmarshal.
+const maxEncodingDepth = 10000
+
+// Patch field numbers used by MarshalBinary
+const (
+	patchMaskField   protowire.Number = 1
+	patchBeforeField protowire.Number = 2
+	patchAfterField  protowire.Number = 3
+)
+
+// MarshalObject encodes a LinearizedObject into a compact binary form. Keys are written
+// in ascending order, so equal objects always produce the same bytes.
+func MarshalObject(obj LinearizedObject) ([]byte, error) {
+	return appendValue(nil, obj)
+}
+
+// UnmarshalObject decodes data produced by MarshalObject. Values nested more than 10000 levels deep
+// are rejected with ErrDepthExceeded, so untrusted data can be decoded safely.
+func UnmarshalObject(data []byte) (LinearizedObject, error) {
+	value, rest, err := consumeValue(data, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(rest))
+	}
+
+	switch obj := value.(type) {
+	case nil:
+		return nil, nil
+	case LinearizedObject:
+		return obj, nil
+	}
+	return nil, fmt.Errorf("%w: expected object but got %T", ErrInvalidEncoding, value)
+}
+
+// MarshalBinary encodes the patch. The mask is stored as its Protobuf encoding and the
+// before and after objects using MarshalObject.
+func (p *Patch) MarshalBinary() ([]byte, error) {
+	var data []byte
+	if p.Mask != nil {
+		mask, err := proto.MarshalOptions{Deterministic: true}.Marshal(p.Mask)
+		if err != nil {
+			return nil, err
+		}
+		data = protowire.AppendTag(data, patchMaskField, protowire.BytesType)
+		data = protowire.AppendBytes(data, mask)
+	}
+
+	for _, part := range []struct {
+		num protowire.Number
+		obj LinearizedObject
+	}{{patchBeforeField, p.Before}, {patchAfterField, p.After}} {
+		if part.obj == nil {
+			continue
+		}
+		encoded, err := MarshalObject(part.obj)
+		if err != nil {
+			return nil, err
+		}
+		data = protowire.AppendTag(data, part.num, protowire.BytesType)
+		data = protowire.AppendBytes(data, encoded)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a patch produced by MarshalBinary.
+func (p *Patch) UnmarshalBinary(data []byte) error {
+	*p = Patch{}
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 || typ != protowire.BytesType {
+			return fmt.Errorf("%w: malformed patch", ErrInvalidEncoding)
+		}
+		data = data[n:]
+
+		field, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return fmt.Errorf("%w: malformed patch", ErrInvalidEncoding)
+		}
+		data = data[n:]
+
+		var err error
+		switch num {
+		case patchMaskField:
+			p.Mask = &UpdateMask{}
+			err = proto.Unmarshal(field, p.Mask)
+		case patchBeforeField:
+			p.Before, err = UnmarshalObject(field)
+		case patchAfterField:
+			p.After, err = UnmarshalObject(field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendValue appends the tagged encoding of a linearized value.
+func appendValue(b []byte, value any) ([]byte, error) {
+	var err error
+	switch v := value.(type) {
+	case nil:
+		return append(b, tagNil), nil
+
+	case LinearizedObject:
+		b = append(b, tagObject)
+		b = protowire.AppendVarint(b, uint64(len(v)))
+		for _, key := range sortedKeys(v) {
+			b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(key)))
+			if b, err = appendValue(b, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	case LinearizedSlice:
+		b = append(b, tagSlice)
+		b = protowire.AppendVarint(b, uint64(len(v)))
+		for _, key := range sortedKeys(v) {
+			b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(key)))
+			if b, err = appendValue(b, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	case LinearizedMap:
+		b = append(b, tagMap)
+		b = protowire.AppendVarint(b, uint64(len(v)))
+		for _, key := range sortedKeys(v) {
+			b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(key)))
+			if b, err = appendValue(b, v[key][0]); err != nil {
+				return nil, err
+			}
+			if b, err = appendValue(b, v[key][1]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	case bool:
+		if v {
+			return append(b, tagBool, 1), nil
+		}
+		return append(b, tagBool, 0), nil
+	case int32:
+		return protowire.AppendVarint(append(b, tagInt32), protowire.EncodeZigZag(int64(v))), nil
+	case int64:
+		return protowire.AppendVarint(append(b, tagInt64), protowire.EncodeZigZag(v)), nil
+	case uint32:
+		return protowire.AppendVarint(append(b, tagUint32), uint64(v)), nil
+	case uint64:
+		return protowire.AppendVarint(append(b, tagUint64), v), nil
+	case float32:
+		return protowire.AppendFixed32(append(b, tagFloat32), math.Float32bits(v)), nil
+	case float64:
+		return protowire.AppendFixed64(append(b, tagFloat64), math.Float64bits(v)), nil
+	case string:
+		return protowire.AppendString(append(b, tagString), v), nil
+	case []byte:
+		return protowire.AppendBytes(append(b, tagBytes), v), nil
+	case protoreflect.EnumNumber:
+		return protowire.AppendVarint(append(b, tagEnum), protowire.EncodeZigZag(int64(v))), nil
+	case Redacted:
+		return append(append(b, tagRedacted), v.Hash[:]...), nil
+	}
+	return nil, fmt.Errorf("cannot encode value of type %T", value)
+}
+
+// consumeValue decodes one tagged value nested depth levels deep and returns the remaining bytes.
+func consumeValue(b []byte, depth int) (any, []byte, error) {
+	if len(b) == 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidEncoding)
+	}
+	if depth > maxEncodingDepth {
+		return nil, nil, fmt.Errorf("%w: %w: values nested more than %d levels", ErrInvalidEncoding, ErrDepthExceeded, maxEncodingDepth)
+	}
+	tag, b := b[0], b[1:]
+
+	switch tag {
+	case tagNil:
+		return nil, b, nil
+
+	case tagObject, tagSlice:
+		count, b, err := consumeVarint(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries := make(map[int32]any, min(count, uint64(len(b))))
+		for i := uint64(0); i < count; i++ {
+			var key int32
+			if key, b, err = consumeKey(b); err != nil {
+				return nil, nil, err
+			}
+			if entries[key], b, err = consumeValue(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+		if tag == tagSlice {
+			return LinearizedSlice(entries), b, nil
+		}
+		return LinearizedObject(entries), b, nil
+
+	case tagMap:
+		count, b, err := consumeVarint(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries := make(LinearizedMap, min(count, uint64(len(b))))
+		for i := uint64(0); i < count; i++ {
+			var key int32
+			var entry [2]any
+			if key, b, err = consumeKey(b); err != nil {
+				return nil, nil, err
+			}
+			if entry[0], b, err = consumeValue(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			if entry[1], b, err = consumeValue(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			entries[key] = entry
+		}
+		return entries, b, nil
+
+	case tagBool:
+		if len(b) == 0 {
+			return nil, nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidEncoding)
+		}
+		return b[0] != 0, b[1:], nil
+
+	case tagInt32, tagInt64, tagEnum:
+		v, b, err := consumeVarint(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		n := protowire.DecodeZigZag(v)
+		switch tag {
+		case tagInt32:
+			return int32(n), b, nil
+		case tagEnum:
+			return protoreflect.EnumNumber(n), b, nil
+		}
+		return n, b, nil
+
+	case tagUint32, tagUint64:
+		v, b, err := consumeVarint(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tag == tagUint32 {
+			return uint32(v), b, nil
+		}
+		return v, b, nil
+
+	case tagFloat32:
+		v, n := protowire.ConsumeFixed32(b)
+		if n < 0 {
+			return nil, nil, fmt.Errorf("%w: malformed float", ErrInvalidEncoding)
+		}
+		return math.Float32frombits(v), b[n:], nil
+
+	case tagFloat64:
+		v, n := protowire.ConsumeFixed64(b)
+		if n < 0 {
+			return nil, nil, fmt.Errorf("%w: malformed double", ErrInvalidEncoding)
+		}
+		return math.Float64frombits(v), b[n:], nil
+
+	case tagString, tagBytes:
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return nil, nil, fmt.Errorf("%w: malformed bytes", ErrInvalidEncoding)
+		}
+		if tag == tagString {
+			return string(v), b[n:], nil
+		}
+		return append([]byte{}, v...), b[n:], nil
+
+	case tagRedacted:
+		var v Redacted
+		if len(b) < len(v.Hash) {
+			return nil, nil, fmt.Errorf("%w: malformed redacted value", ErrInvalidEncoding)
+		}
+		copy(v.Hash[:], b)
+		return v, b[len(v.Hash):], nil
+	}
+	return nil, nil, fmt.Errorf("%w: unknown type tag %d", ErrInvalidEncoding, tag)
+}
+
+func consumeVarint(b []byte) (uint64, []byte, error) {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, nil, fmt.Errorf("%w: malformed varint", ErrInvalidEncoding)
+	}
+	return v, b[n:], nil
+}
+
+func consumeKey(b []byte) (int32, []byte, error) {
+	v, b, err := consumeVarint(b)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int32(protowire.DecodeZigZag(v)), b, nil
+}
+
+// sortedKeys returns the keys of a linearized container in ascending order.
+func sortedKeys[V any](m map[int32]V) []int32 {
+	keys := make([]int32, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,105 @@
+package linearize
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestCodec(t *testing.T) {
+	t.Run("should round trip every value type", func(t *testing.T) {
+		// Arrange
+		obj := LinearizedObject{
+			1:  "text",
+			2:  int32(-7),
+			3:  int64(1 << 40),
+			4:  uint32(7),
+			5:  uint64(1 << 60),
+			6:  float32(1.5),
+			7:  -2.25,
+			8:  true,
+			9:  []byte{0, 1, 2},
+			10: protoreflect.EnumNumber(2),
+			11: LinearizedSlice{0: "a", 1: LinearizedObject{1: "b"}},
+			12: LinearizedMap{0: {"key", LinearizedObject{2: int32(3)}}},
+			13: nil,
+		}
+
+		// Act
+		data, err := MarshalObject(obj)
+		require.NoError(t, err)
+		decoded, err := UnmarshalObject(data)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, obj, decoded)
+	})
+
+	t.Run("should produce identical bytes for equal objects", func(t *testing.T) {
+		// Arrange
+		linearized1, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		linearized2, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+
+		// Act
+		data1, err1 := MarshalObject(linearized1)
+		data2, err2 := MarshalObject(linearized2)
+
+		// Assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		assert.Equal(t, data1, data2)
+	})
+
+	t.Run("should round trip and apply patch", func(t *testing.T) {
+		// Arrange
+		linearized1, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		msg2 := mocks.CreateComplexMessage()
+		msg2.Field1 = "changed_field1"
+		msg2.Nested.Field2 = 1
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+
+		before, after, mask, err := Diff(linearized1, linearized2)
+		require.NoError(t, err)
+		data, err := (&Patch{Mask: mask, Before: before, After: after}).MarshalBinary()
+		require.NoError(t, err)
+
+		// Act
+		var patch Patch
+		err = patch.UnmarshalBinary(data)
+		require.NoError(t, err)
+		err = patch.Apply(linearized1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, linearized2, linearized1)
+	})
+
+	t.Run("should reject malformed data", func(t *testing.T) {
+		// Act
+		_, err := UnmarshalObject([]byte{tagObject, 2, 2})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidEncoding)
+	})
+
+	t.Run("should reject data nested too deeply", func(t *testing.T) {
+		// Arrange
+		data := append(bytes.Repeat([]byte{tagObject, 1, 2}, 1_000_000), tagNil)
+
+		// Act
+		_, err := UnmarshalObject(data)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidEncoding)
+		assert.ErrorIs(t, err, ErrDepthExceeded)
+	})
+}
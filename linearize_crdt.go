@@ -0,0 +1,716 @@
+package linearize
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Timestamp is a hybrid logical clock reading. Timestamps are totally ordered
+// by wall time, then logical counter, then node id, so two replicas never
+// produce the same timestamp.
+type Timestamp struct {
+	WallTime int64  // Physical time in nanoseconds since the Unix epoch
+	Logical  uint32 // Logical counter used when physical time does not advance
+	Node     string // Identifier of the replica that produced the timestamp
+}
+
+// Compare returns -1, 0 or 1 depending on whether t is before, equal to or after other.
+func (t Timestamp) Compare(other Timestamp) int {
+	switch {
+	case t.WallTime != other.WallTime:
+		if t.WallTime < other.WallTime {
+			return -1
+		}
+		return 1
+	case t.Logical != other.Logical:
+		if t.Logical < other.Logical {
+			return -1
+		}
+		return 1
+	case t.Node != other.Node:
+		if t.Node < other.Node {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// After reports whether t is strictly after other.
+func (t Timestamp) After(other Timestamp) bool {
+	return t.Compare(other) > 0
+}
+
+// Clock is a hybrid logical clock producing monotonically increasing timestamps.
+type Clock struct {
+	mu   sync.Mutex
+	node string
+	now  func() int64
+	last Timestamp
+}
+
+// NewClock creates a hybrid logical clock for the given replica.
+func NewClock(node string) *Clock {
+	return &Clock{node: node, now: func() int64 { return time.Now().UnixNano() }}
+}
+
+// Now returns a timestamp greater than any timestamp previously returned or observed.
+func (c *Clock) Now() Timestamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pt := c.now(); pt > c.last.WallTime {
+		c.last = Timestamp{WallTime: pt, Node: c.node}
+	} else {
+		c.last = Timestamp{WallTime: c.last.WallTime, Logical: c.last.Logical + 1, Node: c.node}
+	}
+	return c.last
+}
+
+// Observe advances the clock past a timestamp received from another replica.
+func (c *Clock) Observe(remote Timestamp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wall := max64(c.now(), max64(c.last.WallTime, remote.WallTime))
+	var logical uint32
+	switch {
+	case wall == c.last.WallTime && wall == remote.WallTime:
+		logical = max32(c.last.Logical, remote.Logical) + 1
+	case wall == c.last.WallTime:
+		logical = c.last.Logical + 1
+	case wall == remote.WallTime:
+		logical = remote.Logical + 1
+	}
+	c.last = Timestamp{WallTime: wall, Logical: logical, Node: c.node}
+}
+
+// CRDTNode is a replicated value held by a Replica. Scalars are Registers,
+// messages are ObjectCRDTs, repeated fields are SequenceCRDTs and map fields are MapCRDTs.
+type CRDTNode interface {
+	// join merges other into the node and returns the merged node
+	join(other CRDTNode) CRDTNode
+	// clone returns a deep copy of the node
+	clone() CRDTNode
+	// latest returns the highest timestamp recorded in the node
+	latest() Timestamp
+}
+
+// Register is a last-writer-wins register holding a scalar value. A nil value is a tombstone.
+type Register struct {
+	Value any
+	Stamp Timestamp
+}
+
+// ObjectCRDT holds the fields of a message. Fields written at or before Removed are cleared,
+// and the object itself is present while Added is after Removed.
+type ObjectCRDT struct {
+	Fields  map[int32]CRDTNode
+	Added   Timestamp
+	Removed Timestamp
+}
+
+// SequenceCRDT is a replicated growable array (RGA) used for repeated fields.
+type SequenceCRDT struct {
+	Elements map[Timestamp]*SequenceElement
+}
+
+// SequenceElement is a single element of a SequenceCRDT. Elements are ordered after their
+// Origin, with concurrent inserts after the same origin ordered by descending ID.
+type SequenceElement struct {
+	ID      Timestamp
+	Origin  Timestamp
+	Value   CRDTNode
+	Removed bool
+}
+
+// MapCRDT is an observed-remove map used for map fields.
+type MapCRDT struct {
+	Entries map[string]*MapEntry
+}
+
+// MapEntry is a single entry of a MapCRDT. Each add creates a tag, a remove marks every
+// observed tag as removed; the entry is present while any tag is not removed.
+type MapEntry struct {
+	Key   any
+	Tags  map[Timestamp]bool
+	Value CRDTNode
+}
+
+// Delta is the change produced by a Replica update. State is a fragment of replica state
+// that peers join into their own; Mask is the UpdateMask the change was derived from.
+type Delta struct {
+	Mask  *UpdateMask
+	State *ObjectCRDT
+	Clock Timestamp
+}
+
+// Replica is a conflict-free replicated LinearizedObject. Local edits are recorded with Update,
+// remote edits are received with Apply, and replicas that have seen the same deltas converge
+// to the same state regardless of the order in which the deltas were applied.
+type Replica struct {
+	mu    sync.Mutex
+	clock *Clock
+	root  *ObjectCRDT
+	order MapKeyOrder
+}
+
+// NewReplica creates an empty replica identified by node. Snapshots place map entries in the given
+// key order, which must be the order the objects passed to Update were linearized with.
+func NewReplica(node string, order MapKeyOrder) *Replica {
+	return &Replica{
+		clock: NewClock(node),
+		root:  &ObjectCRDT{Fields: make(map[int32]CRDTNode)},
+		order: order,
+	}
+}
+
+// Update records the changes needed to turn the replica into latest and returns the delta
+// to send to other replicas. A nil delta is returned when there is nothing to change.
+func (r *Replica) Update(latest LinearizedObject) (*Delta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.root.materialize(Timestamp{}, r.order)
+	_, _, mask, err := Diff(current, latest)
+	if err != nil {
+		return nil, err
+	}
+	if mask == nil {
+		return nil, nil
+	}
+
+	fragment := r.objectDelta(r.root, Timestamp{}, current, latest, mask)
+	r.root.join(fragment.clone())
+	return &Delta{Mask: mask, State: fragment, Clock: fragment.latest()}, nil
+}
+
+// Apply joins a delta received from another replica. Applying the same delta more than once
+// has no further effect. Deltas from one replica must be applied in the order they were
+// produced; a replica that missed deltas can catch up by applying another replica's State.
+func (r *Replica) Apply(delta *Delta) error {
+	if delta == nil || delta.State == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clock.Observe(delta.Clock)
+	r.root.join(delta.State.clone())
+	return nil
+}
+
+// State returns the complete replica state as a delta, used to bootstrap a new replica.
+func (r *Replica) State() *Delta {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.root.clone().(*ObjectCRDT)
+	return &Delta{State: state, Clock: state.latest()}
+}
+
+// Snapshot returns the current value of the replica as a LinearizedObject.
+func (r *Replica) Snapshot() LinearizedObject {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.root.materialize(Timestamp{}, r.order)
+}
+
+// Unlinearize exports the current value of the replica into a Protobuf message.
+func (r *Replica) Unlinearize(message proto.Message) error {
+	return Unlinearize(r.Snapshot(), message)
+}
+
+// objectDelta builds the fragment for the fields of an object selected by the mask.
+// Values written at or before floor are hidden from the current state.
+func (r *Replica) objectDelta(state *ObjectCRDT, floor Timestamp, current, latest LinearizedObject, mask *UpdateMask) *ObjectCRDT {
+	if state.Removed.After(floor) {
+		floor = state.Removed
+	}
+
+	fragment := &ObjectCRDT{Fields: make(map[int32]CRDTNode), Added: r.clock.Now()}
+	for pos, maskValue := range mask.Values {
+		node := state.Fields[pos]
+
+		switch maskValue.Op {
+		case UpdateMaskOperation_REMOVE:
+			fragment.Fields[pos] = r.removeDelta(node)
+		case UpdateMaskOperation_ADD, UpdateMaskOperation_UPDATE:
+			if delta := r.valueDelta(node, floor, current[pos], latest[pos], maskValue.Masks); delta != nil {
+				fragment.Fields[pos] = delta
+			}
+		}
+	}
+	return fragment
+}
+
+// valueDelta builds the fragment that changes a node from prev to next.
+func (r *Replica) valueDelta(node CRDTNode, floor Timestamp, prev, next any, mask *UpdateMask) CRDTNode {
+	switch next := next.(type) {
+	case LinearizedObject:
+		state, ok := node.(*ObjectCRDT)
+		prev, isObject := prev.(LinearizedObject)
+		if !ok || !isObject {
+			return r.newNode(next)
+		}
+		if mask == nil || len(mask.Values) == 0 {
+			_, _, mask, _ = Diff(prev, next)
+		}
+		if mask == nil {
+			return nil
+		}
+		return r.objectDelta(state, floor, prev, next, mask)
+
+	case LinearizedSlice:
+		state, ok := node.(*SequenceCRDT)
+		if !ok {
+			return r.newNode(next)
+		}
+		prev, _ := prev.(LinearizedSlice)
+		return r.sequenceDelta(state, floor, prev, next)
+
+	case LinearizedMap:
+		state, ok := node.(*MapCRDT)
+		if !ok {
+			return r.newNode(next)
+		}
+		prev, _ := prev.(LinearizedMap)
+		return r.mapDelta(state, floor, prev, next)
+
+	default:
+		return &Register{Value: next, Stamp: r.clock.Now()}
+	}
+}
+
+// sequenceDelta updates, appends and removes elements by their visible position.
+func (r *Replica) sequenceDelta(state *SequenceCRDT, floor Timestamp, prev, next LinearizedSlice) CRDTNode {
+	visible := state.visible(floor)
+	fragment := &SequenceCRDT{Elements: make(map[Timestamp]*SequenceElement)}
+
+	var origin Timestamp
+	for i, elem := range visible {
+		origin = elem.ID
+		nextValue, exists := next[int32(i)]
+		if !exists {
+			fragment.Elements[elem.ID] = &SequenceElement{ID: elem.ID, Origin: elem.Origin, Removed: true}
+			continue
+		}
+		if changed, _, _, _ := compareValues(prev[int32(i)], nextValue); changed {
+			if delta := r.valueDelta(elem.Value, floor, prev[int32(i)], nextValue, nil); delta != nil {
+				fragment.Elements[elem.ID] = &SequenceElement{ID: elem.ID, Origin: elem.Origin, Value: delta}
+			}
+		}
+	}
+
+	for i := len(visible); i < len(next); i++ {
+		id := r.clock.Now()
+		fragment.Elements[id] = &SequenceElement{ID: id, Origin: origin, Value: r.newNode(next[int32(i)])}
+		origin = id
+	}
+
+	if len(fragment.Elements) == 0 {
+		return nil
+	}
+	return fragment
+}
+
+// mapDelta adds, updates and removes entries by key.
+func (r *Replica) mapDelta(state *MapCRDT, floor Timestamp, prev, next LinearizedMap) CRDTNode {
+	prevByKey := make(map[string]any, len(prev))
+	for _, kv := range prev {
+		prevByKey[crdtKey(kv[0])] = kv[1]
+	}
+
+	fragment := &MapCRDT{Entries: make(map[string]*MapEntry)}
+	nextKeys := make(map[string]bool, len(next))
+	for _, kv := range next {
+		key := crdtKey(kv[0])
+		nextKeys[key] = true
+
+		prevValue, exists := prevByKey[key]
+		entry := state.Entries[key]
+		if !exists || entry == nil {
+			tag := r.clock.Now()
+			fragment.Entries[key] = &MapEntry{Key: kv[0], Tags: map[Timestamp]bool{tag: false}, Value: r.newNode(kv[1])}
+			continue
+		}
+		if changed, _, _, _ := compareValues(prevValue, kv[1]); changed {
+			if delta := r.valueDelta(entry.Value, floor, prevValue, kv[1], nil); delta != nil {
+				fragment.Entries[key] = &MapEntry{Key: kv[0], Tags: map[Timestamp]bool{}, Value: delta}
+			}
+		}
+	}
+
+	for key := range prevByKey {
+		if nextKeys[key] {
+			continue
+		}
+		if entry := state.Entries[key]; entry != nil {
+			fragment.Entries[key] = &MapEntry{Key: entry.Key, Tags: removedTags(entry.Tags)}
+		}
+	}
+
+	if len(fragment.Entries) == 0 {
+		return nil
+	}
+	return fragment
+}
+
+// removeDelta builds the fragment that removes the value held by a field.
+func (r *Replica) removeDelta(node CRDTNode) CRDTNode {
+	switch state := node.(type) {
+	case *ObjectCRDT:
+		return &ObjectCRDT{Fields: make(map[int32]CRDTNode), Removed: r.clock.Now()}
+	case *SequenceCRDT:
+		fragment := &SequenceCRDT{Elements: make(map[Timestamp]*SequenceElement)}
+		for id, elem := range state.Elements {
+			fragment.Elements[id] = &SequenceElement{ID: id, Origin: elem.Origin, Removed: true}
+		}
+		return fragment
+	case *MapCRDT:
+		fragment := &MapCRDT{Entries: make(map[string]*MapEntry)}
+		for key, entry := range state.Entries {
+			fragment.Entries[key] = &MapEntry{Key: entry.Key, Tags: removedTags(entry.Tags)}
+		}
+		return fragment
+	}
+	return &Register{Stamp: r.clock.Now()}
+}
+
+// newNode builds the state for a value that replaces whatever the field held before.
+func (r *Replica) newNode(value any) CRDTNode {
+	switch value := value.(type) {
+	case LinearizedObject:
+		obj := &ObjectCRDT{Fields: make(map[int32]CRDTNode), Removed: r.clock.Now()}
+		obj.Added = r.clock.Now()
+		for key, v := range value {
+			if node := r.newNode(v); node != nil {
+				obj.Fields[key] = node
+			}
+		}
+		return obj
+	case LinearizedSlice:
+		return r.sequenceDelta(&SequenceCRDT{}, Timestamp{}, nil, value)
+	case LinearizedMap:
+		return r.mapDelta(&MapCRDT{}, Timestamp{}, nil, value)
+	default:
+		return &Register{Value: value, Stamp: r.clock.Now()}
+	}
+}
+
+func (reg *Register) join(other CRDTNode) CRDTNode {
+	if o, ok := other.(*Register); ok {
+		if o.Stamp.After(reg.Stamp) {
+			*reg = *o
+		}
+		return reg
+	}
+	return joinMismatched(reg, other)
+}
+
+func (reg *Register) clone() CRDTNode {
+	c := *reg
+	return &c
+}
+
+func (reg *Register) latest() Timestamp {
+	return reg.Stamp
+}
+
+func (obj *ObjectCRDT) join(other CRDTNode) CRDTNode {
+	o, ok := other.(*ObjectCRDT)
+	if !ok {
+		return joinMismatched(obj, other)
+	}
+	if o.Added.After(obj.Added) {
+		obj.Added = o.Added
+	}
+	if o.Removed.After(obj.Removed) {
+		obj.Removed = o.Removed
+	}
+	if obj.Fields == nil {
+		obj.Fields = make(map[int32]CRDTNode, len(o.Fields))
+	}
+	for key, node := range o.Fields {
+		obj.Fields[key] = joinNodes(obj.Fields[key], node)
+	}
+	return obj
+}
+
+func (obj *ObjectCRDT) clone() CRDTNode {
+	c := &ObjectCRDT{Fields: make(map[int32]CRDTNode, len(obj.Fields)), Added: obj.Added, Removed: obj.Removed}
+	for key, node := range obj.Fields {
+		c.Fields[key] = node.clone()
+	}
+	return c
+}
+
+func (obj *ObjectCRDT) latest() Timestamp {
+	ts := obj.Added
+	if obj.Removed.After(ts) {
+		ts = obj.Removed
+	}
+	for _, node := range obj.Fields {
+		if l := node.latest(); l.After(ts) {
+			ts = l
+		}
+	}
+	return ts
+}
+
+// materialize returns the visible fields of the object, hiding values written at or before floor.
+func (obj *ObjectCRDT) materialize(floor Timestamp, order MapKeyOrder) LinearizedObject {
+	if obj.Removed.After(floor) {
+		floor = obj.Removed
+	}
+
+	result := make(LinearizedObject)
+	for key, node := range obj.Fields {
+		if value := materializeNode(node, floor, order); value != nil {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func (seq *SequenceCRDT) join(other CRDTNode) CRDTNode {
+	o, ok := other.(*SequenceCRDT)
+	if !ok {
+		return joinMismatched(seq, other)
+	}
+	if seq.Elements == nil {
+		seq.Elements = make(map[Timestamp]*SequenceElement, len(o.Elements))
+	}
+	for id, elem := range o.Elements {
+		existing, exists := seq.Elements[id]
+		if !exists {
+			seq.Elements[id] = elem
+			continue
+		}
+		existing.Removed = existing.Removed || elem.Removed
+		existing.Value = joinNodes(existing.Value, elem.Value)
+	}
+	return seq
+}
+
+func (seq *SequenceCRDT) clone() CRDTNode {
+	c := &SequenceCRDT{Elements: make(map[Timestamp]*SequenceElement, len(seq.Elements))}
+	for id, elem := range seq.Elements {
+		e := *elem
+		if elem.Value != nil {
+			e.Value = elem.Value.clone()
+		}
+		c.Elements[id] = &e
+	}
+	return c
+}
+
+func (seq *SequenceCRDT) latest() Timestamp {
+	var ts Timestamp
+	for id, elem := range seq.Elements {
+		if id.After(ts) {
+			ts = id
+		}
+		if elem.Value != nil {
+			if l := elem.Value.latest(); l.After(ts) {
+				ts = l
+			}
+		}
+	}
+	return ts
+}
+
+// visible returns the elements in RGA order, skipping removed elements and elements
+// inserted at or before floor.
+func (seq *SequenceCRDT) visible(floor Timestamp) []*SequenceElement {
+	children := make(map[Timestamp][]*SequenceElement)
+	for _, elem := range seq.Elements {
+		children[elem.Origin] = append(children[elem.Origin], elem)
+	}
+	for _, siblings := range children {
+		sort.Slice(siblings, func(i, j int) bool {
+			return siblings[i].ID.After(siblings[j].ID)
+		})
+	}
+
+	var ordered []*SequenceElement
+	var walk func(origin Timestamp)
+	walk = func(origin Timestamp) {
+		for _, elem := range children[origin] {
+			if !elem.Removed && elem.Value != nil && elem.ID.After(floor) {
+				ordered = append(ordered, elem)
+			}
+			walk(elem.ID)
+		}
+	}
+	walk(Timestamp{})
+	return ordered
+}
+
+func (m *MapCRDT) join(other CRDTNode) CRDTNode {
+	o, ok := other.(*MapCRDT)
+	if !ok {
+		return joinMismatched(m, other)
+	}
+	if m.Entries == nil {
+		m.Entries = make(map[string]*MapEntry, len(o.Entries))
+	}
+	for key, entry := range o.Entries {
+		existing, exists := m.Entries[key]
+		if !exists {
+			m.Entries[key] = entry
+			continue
+		}
+		if existing.Tags == nil {
+			existing.Tags = make(map[Timestamp]bool, len(entry.Tags))
+		}
+		for tag, removed := range entry.Tags {
+			existing.Tags[tag] = existing.Tags[tag] || removed
+		}
+		existing.Value = joinNodes(existing.Value, entry.Value)
+	}
+	return m
+}
+
+func (m *MapCRDT) clone() CRDTNode {
+	c := &MapCRDT{Entries: make(map[string]*MapEntry, len(m.Entries))}
+	for key, entry := range m.Entries {
+		e := &MapEntry{Key: entry.Key, Tags: make(map[Timestamp]bool, len(entry.Tags))}
+		for tag, removed := range entry.Tags {
+			e.Tags[tag] = removed
+		}
+		if entry.Value != nil {
+			e.Value = entry.Value.clone()
+		}
+		c.Entries[key] = e
+	}
+	return c
+}
+
+func (m *MapCRDT) latest() Timestamp {
+	var ts Timestamp
+	for _, entry := range m.Entries {
+		for tag := range entry.Tags {
+			if tag.After(ts) {
+				ts = tag
+			}
+		}
+		if entry.Value != nil {
+			if l := entry.Value.latest(); l.After(ts) {
+				ts = l
+			}
+		}
+	}
+	return ts
+}
+
+// materialize returns the present entries in the key order, matching the layout produced by Linearize.
+func (m *MapCRDT) materialize(floor Timestamp, order MapKeyOrder) LinearizedMap {
+	result := make(LinearizedMap, len(m.Entries))
+	for _, entry := range m.Entries {
+		present := false
+		for tag, removed := range entry.Tags {
+			if !removed && tag.After(floor) {
+				present = true
+				break
+			}
+		}
+		if !present {
+			continue
+		}
+		if value := materializeNode(entry.Value, floor, order); value != nil {
+			result[int32(len(result))] = [2]any{entry.Key, value}
+		}
+	}
+
+	sortMapEntries(result, order)
+	return result
+}
+
+// materializeNode returns the visible value of a node, or nil when it is absent.
+func materializeNode(node CRDTNode, floor Timestamp, order MapKeyOrder) any {
+	switch node := node.(type) {
+	case *Register:
+		if node.Value == nil || !node.Stamp.After(floor) {
+			return nil
+		}
+		return node.Value
+	case *ObjectCRDT:
+		if !node.Added.After(node.Removed) || !node.Added.After(floor) {
+			return nil
+		}
+		return node.materialize(floor, order)
+	case *SequenceCRDT:
+		visible := node.visible(floor)
+		if len(visible) == 0 {
+			return nil
+		}
+		result := make(LinearizedSlice, len(visible))
+		for i, elem := range visible {
+			result[int32(i)] = materializeNode(elem.Value, floor, order)
+		}
+		return result
+	case *MapCRDT:
+		result := node.materialize(floor, order)
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	}
+	return nil
+}
+
+// joinNodes merges two possibly nil nodes.
+func joinNodes(a, b CRDTNode) CRDTNode {
+	switch {
+	case a == nil && b == nil:
+		return nil
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	return a.join(b)
+}
+
+// joinMismatched resolves nodes of different kinds by keeping the most recently written one.
+func joinMismatched(a, b CRDTNode) CRDTNode {
+	if b.latest().After(a.latest()) {
+		return b
+	}
+	return a
+}
+
+// removedTags marks every observed tag as removed.
+func removedTags(tags map[Timestamp]bool) map[Timestamp]bool {
+	removed := make(map[Timestamp]bool, len(tags))
+	for tag := range tags {
+		removed[tag] = true
+	}
+	return removed
+}
+
+// crdtKey returns the identity of a map key within a MapCRDT.
+func crdtKey(key any) string {
+	return fmt.Sprintf("%T:%v", key, key)
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+	return b
+}
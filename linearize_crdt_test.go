@@ -0,0 +1,169 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReplica(t *testing.T) {
+	// newReplicas creates two replicas that share the initial state of msg
+	newReplicas := func(t *testing.T, msg proto.Message) (*Replica, *Replica) {
+		linearized, err := Linearize(msg)
+		require.NoError(t, err)
+
+		a := NewReplica("a", MapKeysLexical)
+		_, err = a.Update(linearized)
+		require.NoError(t, err)
+
+		b := NewReplica("b", MapKeysLexical)
+		require.NoError(t, b.Apply(a.State()))
+		return a, b
+	}
+
+	t.Run("should export replica state through unlinearize", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateComplexMessage()
+		a, b := newReplicas(t, msg)
+
+		// Act
+		var exported mocks.Complex
+		err := b.Unlinearize(&exported)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(msg, &exported))
+		assert.Equal(t, a.Snapshot(), b.Snapshot())
+	})
+
+	t.Run("should converge after concurrent updates", func(t *testing.T) {
+		// Arrange
+		a, b := newReplicas(t, mocks.CreateComplexMessage())
+
+		msgA := mocks.CreateComplexMessage()
+		msgA.Field1 = "changed_by_a"
+		msgA.Repeated = append(msgA.Repeated, &mocks.Simple{Field1: "appended_by_a"})
+		delete(msgA.Map, "key1")
+		linearizedA, err := Linearize(msgA)
+		require.NoError(t, err)
+
+		msgB := mocks.CreateComplexMessage()
+		msgB.Field2 = 300
+		msgB.Repeated = append(msgB.Repeated, &mocks.Simple{Field1: "appended_by_b"})
+		msgB.Map["key3"] = &mocks.Simple{Field1: "added_by_b"}
+		linearizedB, err := Linearize(msgB)
+		require.NoError(t, err)
+
+		// Act
+		deltaA, err := a.Update(linearizedA)
+		require.NoError(t, err)
+		deltaB, err := b.Update(linearizedB)
+		require.NoError(t, err)
+
+		require.NoError(t, a.Apply(deltaB))
+		require.NoError(t, b.Apply(deltaA))
+
+		// Assert
+		assert.Equal(t, a.Snapshot(), b.Snapshot())
+
+		var merged mocks.Complex
+		require.NoError(t, a.Unlinearize(&merged))
+		assert.Equal(t, "changed_by_a", merged.Field1)
+		assert.Equal(t, int32(300), merged.Field2)
+		assert.Len(t, merged.Repeated, 4)
+		assert.NotContains(t, merged.Map, "key1")
+		assert.Contains(t, merged.Map, "key2")
+		assert.Contains(t, merged.Map, "key3")
+	})
+
+	t.Run("should resolve concurrent writes to the same field with the latest write", func(t *testing.T) {
+		// Arrange
+		a, b := newReplicas(t, mocks.CreateSimpleMessage())
+
+		msgA := mocks.CreateSimpleMessage()
+		msgA.Field1 = "written_by_a"
+		linearizedA, err := Linearize(msgA)
+		require.NoError(t, err)
+
+		msgB := mocks.CreateSimpleMessage()
+		msgB.Field1 = "written_by_b"
+		linearizedB, err := Linearize(msgB)
+		require.NoError(t, err)
+
+		// Act
+		deltaA, err := a.Update(linearizedA)
+		require.NoError(t, err)
+		deltaB, err := b.Update(linearizedB)
+		require.NoError(t, err)
+
+		require.NoError(t, b.Apply(deltaA))
+		require.NoError(t, a.Apply(deltaB))
+
+		// Assert
+		winner := "written_by_a"
+		if deltaB.Clock.After(deltaA.Clock) {
+			winner = "written_by_b"
+		}
+		assert.Equal(t, winner, a.Snapshot()[1])
+		assert.Equal(t, a.Snapshot(), b.Snapshot())
+	})
+
+	t.Run("should ignore duplicate deltas", func(t *testing.T) {
+		// Arrange
+		a, b := newReplicas(t, mocks.CreateSimpleMessage())
+
+		msg := mocks.CreateSimpleMessage()
+		msg.Repeated = append(msg.Repeated, "value3")
+		linearized, err := Linearize(msg)
+		require.NoError(t, err)
+
+		delta, err := a.Update(linearized)
+		require.NoError(t, err)
+
+		// Act
+		require.NoError(t, b.Apply(delta))
+		require.NoError(t, b.Apply(delta))
+
+		// Assert
+		assert.Equal(t, linearized, b.Snapshot())
+	})
+
+	t.Run("should return nil delta when nothing changed", func(t *testing.T) {
+		// Arrange
+		a, _ := newReplicas(t, mocks.CreateSimpleMessage())
+
+		// Act
+		delta, err := a.Update(a.Snapshot())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, delta)
+	})
+
+	t.Run("should place map entries in the replica's key order", func(t *testing.T) {
+		// Arrange
+		order := WithMapKeyOrder(MapKeysNatural)
+		msg := &mocks.SuperComplex{Map: map[int32]*mocks.Complex{
+			2:  {Field1: "two"},
+			10: {Field1: "ten"},
+		}}
+		linearized, err := Linearize(msg, order)
+		require.NoError(t, err)
+		replica := NewReplica("a", MapKeysNatural)
+		_, err = replica.Update(linearized)
+		require.NoError(t, err)
+		msg.Map[3] = &mocks.Complex{Field1: "three"}
+		latest, err := Linearize(msg, order)
+		require.NoError(t, err)
+
+		// Act
+		_, err = replica.Update(latest)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, latest, replica.Snapshot())
+	})
+}
@@ -1,24 +1,244 @@
 package linearize
 
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DiffOption configures Diff.
+type DiffOption interface {
+	applyDiff(*diffOptions)
+}
+
+type diffOptions struct {
+	md              protoreflect.MessageDescriptor
+	filters         []*FieldFilter
+	redactMD        protoreflect.MessageDescriptor
+	salt            []byte
+	tolerance       FloatTolerance
+	fieldTolerances map[protoreflect.FieldDescriptor]FloatTolerance
+	maxDepth        int
+	listStrategy    ListStrategy
+	limits          Limits
+	workers         int
+	ctx             context.Context
+	slots           chan struct{} // extra goroutines the comparison may start
+	mu              sync.Mutex
+	err             error // first error found during the comparison
+}
+
+// parallelChunk is the number of slice elements or map entries compared by one worker.
+const parallelChunk = 256
+
+// WithParallelism makes Diff compare top-level fields, and large repeated and map fields in chunks,
+// on up to workers goroutines. The result is identical to a sequential Diff.
+func WithParallelism(workers int) DiffOption {
+	return diffOptionFunc(func(o *diffOptions) {
+		o.workers = workers
+	})
+}
+
+// ListStrategy determines how Diff compares repeated fields.
+type ListStrategy int
+
+const (
+	// ListByPosition compares elements at the same index and reports changes per element.
+	ListByPosition ListStrategy = iota
+	// ListReplace reports any change to a repeated field as an update of the whole field.
+	ListReplace
+)
+
+// WithListStrategy sets how Diff compares repeated fields.
+func WithListStrategy(strategy ListStrategy) DiffOption {
+	return diffOptionFunc(func(o *diffOptions) {
+		o.listStrategy = strategy
+	})
+}
+
+// diffOptionFunc adapts a function to a DiffOption.
+type diffOptionFunc func(*diffOptions)
+
+func (f diffOptionFunc) applyDiff(o *diffOptions) {
+	f(o)
+}
+
+func newDiffOptions(opts []DiffOption) *diffOptions {
+	options := &diffOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt.applyDiff(options)
+	}
+	if options.workers > 1 {
+		options.slots = make(chan struct{}, options.workers-1)
+	}
+	return options
+}
+
+// fail records the first error found during the comparison.
+func (o *diffOptions) fail(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// cancelled reports whether the context is done, recording its error.
+func (o *diffOptions) cancelled() bool {
+	if err := o.ctx.Err(); err != nil {
+		o.fail(err)
+		return true
+	}
+	return false
+}
+
+// finish reports errors recorded during the comparison and applies WithRedaction to a Diff result.
+func (o *diffOptions) finish(before, after LinearizedObject, mask *UpdateMask, err error) (LinearizedObject, LinearizedObject, *UpdateMask, error) {
+	if err == nil {
+		err = o.err
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if o.redactMD == nil || !hasSensitiveFields(o.redactMD) {
+		return before, after, mask, nil
+	}
+	return Redact(before, o.redactMD, o.salt), Redact(after, o.redactMD, o.salt), mask, nil
+}
+
 // Diff compares two LinearizedObject maps and returns before, after, and a single mask.
-func Diff(previous, latest LinearizedObject) (before LinearizedObject, after LinearizedObject, mask *UpdateMask, err error) {
+func Diff(previous, latest LinearizedObject, opts ...DiffOption) (before LinearizedObject, after LinearizedObject, mask *UpdateMask, err error) {
+	return newDiffOptions(opts).run(previous, latest, nil, nil)
+}
+
+// DiffContext compares two objects like Diff, returning the context's error once it is done.
+func DiffContext(ctx context.Context, previous, latest LinearizedObject, opts ...DiffOption) (before LinearizedObject, after LinearizedObject, mask *UpdateMask, err error) {
+	options := newDiffOptions(opts)
+	options.ctx = ctx
+	return options.run(previous, latest, nil, nil)
+}
+
+// DiffMerkle compares two LinearizedObject maps like Diff, using their Merkle trees to skip
+// identical subtrees without visiting them. The trees must have been built from the objects.
+func DiffMerkle(previous, latest LinearizedObject, previousTree, latestTree *MerkleNode, opts ...DiffOption) (before LinearizedObject, after LinearizedObject, mask *UpdateMask, err error) {
+	return newDiffOptions(opts).run(previous, latest, previousTree, latestTree)
+}
+
+// run checks both objects against the limits and compares them.
+func (o *diffOptions) run(previous, latest LinearizedObject, previousTree, latestTree *MerkleNode) (LinearizedObject, LinearizedObject, *UpdateMask, error) {
+	for _, obj := range []LinearizedObject{previous, latest} {
+		if err := o.limits.check(o.ctx, obj); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return o.finish(o.differ().diff(previous, latest, previousTree, latestTree))
+}
+
+// differ carries the state shared by a comparison. When the descriptor is known, md is the message
+// of the objects being compared and fd the field whose values are being compared. depth is the
+// nesting level of the object holding the field.
+type differ struct {
+	scope fieldScope
+	md    protoreflect.MessageDescriptor
+	fd    protoreflect.FieldDescriptor
+	opts  *diffOptions
+	depth int
+}
+
+// differ returns the differ for the top-level objects.
+func (o *diffOptions) differ() differ {
+	return differ{scope: newFieldScope(o.filters), md: o.md, opts: o}
+}
+
+// field returns the differ for the value of a field, or false when the field is filtered out
+// or nested too deeply.
+func (d differ) field(key int32) (differ, bool) {
+	scope, ok := d.scope.field(key)
+	child := differ{scope: scope, opts: d.opts, depth: d.depth + 1}
+	if d.opts != nil && d.opts.maxDepth > 0 && child.depth > d.opts.maxDepth {
+		d.opts.fail(fmt.Errorf("%w: %d", ErrDepthExceeded, d.opts.maxDepth))
+		return child, false
+	}
+	if d.md != nil {
+		child.fd = d.md.Fields().ByNumber(protoreflect.FieldNumber(key))
+	}
+	if child.fd != nil {
+		child.md = child.fd.Message()
+		if child.fd.IsMap() {
+			child.md = child.fd.MapValue().Message()
+		}
+	}
+	return child, ok
+}
+
+// sameElement reports whether two elements of a repeated field with a list_key have the same key,
+// for lists whose elements could not be matched by key. Elements without a key, or that are not
+// messages, are always treated as the same element.
+func (d differ) sameElement(prevElem, latestElem any) bool {
+	name := fieldOptions(d.fd).GetListKey()
+	if name == "" || d.md == nil {
+		return true
+	}
+	keyField := d.md.Fields().ByName(protoreflect.Name(name))
+	prevObj, prevOk := prevElem.(LinearizedObject)
+	latestObj, latestOk := latestElem.(LinearizedObject)
+	if keyField == nil || !prevOk || !latestOk {
+		return true
+	}
+	key := int32(keyField.Number())
+	return equalValues(prevObj[key], latestObj[key])
+}
+
+// diff compares two objects, using the Merkle nodes (when present) to skip identical subtrees.
+func (d differ) diff(previous, latest LinearizedObject, previousNode, latestNode *MerkleNode) (before LinearizedObject, after LinearizedObject, mask *UpdateMask, err error) {
+	if previousNode != nil && latestNode != nil && previousNode.Hash == latestNode.Hash {
+		return nil, nil, nil, nil
+	}
+
 	before = make(LinearizedObject)
 	after = make(LinearizedObject)
 	masks := make(map[int32]*UpdateMaskValue) // Map of masks for each key
 
-	// Iterate over the previous map to find removed or changed keys
-	for key, prevValue := range previous {
-		pos := int32(key)
+	// Compare the keys of the previous map, fanning out over them when parallelism is enabled
+	keys := make([]int32, 0, len(previous))
+	for key := range previous {
+		keys = append(keys, key)
+	}
+	results := make([]comparison, len(keys))
+	d.parallel(len(keys), 1, func(i int) {
+		key := keys[i]
+		child, ok := d.field(key)
+		if !ok {
+			results[i].skipped = true
+			return
+		}
 		latestValue, exists := latest[key]
 		if !exists {
+			results[i].removed = true
+			return
+		}
+		results[i].changed, results[i].before, results[i].after, results[i].mask = child.compare(previous[key], latestValue, previousNode.Child(key), latestNode.Child(key))
+	})
+
+	// Iterate over the previous map to find removed or changed keys
+	for i, key := range keys {
+		pos := int32(key)
+		result := results[i]
+		if result.skipped {
+			continue
+		}
+		if result.removed {
 			// If key is removed, mark it for removal in the mask
-			before[key] = prevValue
+			before[key] = previous[key]
 			after[key] = nil
 			masks[pos] = &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE}
 			continue
 		}
 
-		changed, nestedBefore, nestedAfter, nestedMask := compareValues(prevValue, latestValue)
+		changed, nestedBefore, nestedAfter, nestedMask := result.changed, result.before, result.after, result.mask
 		if changed {
 			// If there is a change, add the before/after values and the nested mask (if present)
 			before[key] = nestedBefore
@@ -35,6 +255,9 @@ func Diff(previous, latest LinearizedObject) (before LinearizedObject, after Lin
 	// Check for keys added in the latest version that are not in the previous version
 	for key, latestValue := range latest {
 		pos := int32(key)
+		if _, ok := d.field(key); !ok {
+			continue
+		}
 		if _, exists := previous[key]; !exists {
 			before[key] = nil
 			after[key] = latestValue
@@ -54,6 +277,30 @@ func Diff(previous, latest LinearizedObject) (before LinearizedObject, after Lin
 
 // compareValues compares two values and returns if they have changed and the mask.
 func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nestedAfter any, nestedMask *UpdateMask) {
+	return differ{}.compare(prevValue, latestValue, nil, nil)
+}
+
+// compare compares two values and returns if they have changed and the mask. When both
+// Merkle nodes are present and their hashes match, the values are unchanged.
+func (d differ) compare(prevValue, latestValue any, prevNode, latestNode *MerkleNode) (changed bool, nestedBefore, nestedAfter any, nestedMask *UpdateMask) {
+	// Identical subtrees need no further comparison
+	if prevNode != nil && latestNode != nil && prevNode.Hash == latestNode.Hash {
+		if prevNode.Children != nil {
+			return false, prevValue, latestValue, nil
+		}
+		return false, nil, nil, nil
+	}
+
+	// Atomic messages change as a whole
+	if fieldOptions(d.fd).GetAtomic() {
+		if _, ok := prevValue.(LinearizedObject); ok {
+			if equalValues(prevValue, latestValue) {
+				return false, prevValue, latestValue, nil
+			}
+			return true, prevValue, latestValue, nil
+		}
+	}
+
 	// Initialize a new UpdateMask
 	nestedMask = &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
 
@@ -68,6 +315,15 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 
 			// Compare keys that are in both objects
 			for key, prevVal := range prev {
+				child, ok := d.field(key)
+				if !ok {
+					// Filtered fields are carried over unchanged
+					nestedBefore.(LinearizedObject)[key] = prevVal
+					if latestVal, exists := latest[key]; exists {
+						nestedAfter.(LinearizedObject)[key] = latestVal
+					}
+					continue
+				}
 				latestVal, exists := latest[key]
 				if !exists {
 					// Key was removed in the latest object
@@ -79,7 +335,7 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 				}
 
 				// Compare values recursively
-				elemChanged, elemBefore, elemAfter, elemMask := compareValues(prevVal, latestVal)
+				elemChanged, elemBefore, elemAfter, elemMask := child.compare(prevVal, latestVal, prevNode.Child(key), latestNode.Child(key))
 				if elemChanged {
 					// Update nestedBefore and nestedAfter with the changed values for this key
 					nestedBefore.(LinearizedObject)[key] = elemBefore
@@ -104,6 +360,10 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 			// Check for new keys in the latest object
 			for key, latestVal := range latest {
 				if _, exists := prev[key]; !exists {
+					if _, ok := d.field(key); !ok {
+						nestedAfter.(LinearizedObject)[key] = latestVal
+						continue
+					}
 					// If key is new, add it to the after state with an empty before state
 					nestedBefore.(LinearizedObject)[key] = nil
 					nestedAfter.(LinearizedObject)[key] = latestVal
@@ -118,6 +378,19 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 
 	case LinearizedSlice:
 		if latest, ok := latestValue.(LinearizedSlice); ok {
+			if d.opts != nil && d.opts.listStrategy == ListReplace {
+				if equalValues(prev, latest) {
+					return false, prev, latest, nil
+				}
+				return true, prev, latest, nil
+			}
+
+			// Elements of lists with a list_key are matched by key
+			if key, ok := listKey(d.fd); ok {
+				if ok, changed, before, after, mask := d.compareKeyed(key, prev, latest, prevNode, latestNode); ok {
+					return changed, before, after, mask
+				}
+			}
 
 			changed = false
 			prevLen := len(prev)
@@ -127,7 +400,9 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 			mergedBefore := make(LinearizedSlice, maxLen)
 			mergedAfter := make(LinearizedSlice, maxLen)
 
-			for i := 0; i < maxLen; i++ {
+			// Compare elements, fanning out over chunks of large slices when parallelism is enabled
+			results := make([]comparison, maxLen)
+			d.parallel(maxLen, parallelChunk, func(i int) {
 				key := int32(i)
 
 				var prevElem, latestElem any
@@ -138,8 +413,18 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 					latestElem = latest[key]
 				}
 
-				// Compare elements
-				elemChanged, elemBefore, elemAfter, elemMask := compareValues(prevElem, latestElem)
+				// Compare elements, replacing elements whose list key changed instead of diffing their fields
+				result := &results[i]
+				if d.sameElement(prevElem, latestElem) {
+					result.changed, result.before, result.after, result.mask = d.compare(prevElem, latestElem, prevNode.Child(key), latestNode.Child(key))
+				} else {
+					result.changed, result.before, result.after = true, prevElem, latestElem
+				}
+			})
+
+			for i, result := range results {
+				key := int32(i)
+				elemChanged, elemBefore, elemAfter, elemMask := result.changed, result.before, result.after, result.mask
 				mergedBefore[key] = elemBefore
 				if elemChanged {
 					changed = true
@@ -170,8 +455,23 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 			mergedBefore := make(LinearizedMap)
 			mergedAfter := make(LinearizedMap)
 
+			// Compare entries present in both maps, fanning out over chunks of large maps when parallelism is enabled
+			positions := make([]int32, 0, len(prev))
+			for key := range prev {
+				positions = append(positions, key)
+			}
+			results := make([]comparison, len(positions))
+			d.parallel(len(positions), parallelChunk, func(i int) {
+				key := positions[i]
+				if latestVal, exists := latest[key]; exists {
+					result := &results[i]
+					result.changed, result.before, result.after, result.mask = d.compare(prev[key], latestVal, prevNode.Child(key), latestNode.Child(key))
+				}
+			})
+
 			// Check keys in the previous map (prev) and compare with the latest map
-			for key, prevVal := range prev {
+			for i, key := range positions {
+				prevVal := prev[key]
 
 				// Check if the key is present in the latest map
 				latestVal, exists := latest[key]
@@ -187,7 +487,7 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 				}
 
 				// If key is present in both maps, compare the values
-				elemChanged, elemBefore, elemAfter, elemMask := compareValues(prevVal, latestVal)
+				elemChanged, elemBefore, elemAfter, elemMask := results[i].changed, results[i].before, results[i].after, results[i].mask
 
 				// Cast elemBefore and elemAfter to [2]any
 				if elemBefore != nil {
@@ -232,17 +532,97 @@ func compareValues(prevValue, latestValue any) (changed bool, nestedBefore, nest
 			return changed, mergedBefore, mergedAfter, nestedMask
 		}
 
+	case [2]any:
+		if latest, ok := latestValue.([2]any); ok {
+			// Compare map entries by key first, then by value
+			if keyChanged, _, _, _ := d.compare(prev[0], latest[0], prevNode.Child(0), latestNode.Child(0)); keyChanged {
+				return true, prev, latest, nil
+			}
+			if valueChanged, _, _, valueMask := d.compare(prev[1], latest[1], prevNode.Child(1), latestNode.Child(1)); valueChanged {
+				return true, prev, latest, valueMask
+			}
+			return false, prev, latest, nil
+		}
+
 	default:
+		// Float changes within the field's tolerance are ignored, but a float replaced by a double is a change
+		prevFloat, prevOk := floatValue(prevValue)
+		latestFloat, latestOk := floatValue(latestValue)
+		if prevOk && latestOk && reflect.TypeOf(prevValue) == reflect.TypeOf(latestValue) {
+			if !d.floatTolerance().Equal(prevFloat, latestFloat) {
+				return true, prevValue, latestValue, nil
+			}
+			return false, nil, nil, nil
+		}
+
 		// Handle primitive values directly
-		if prevValue != latestValue {
+		if !equalScalars(prevValue, latestValue) {
 			return true, prevValue, latestValue, nil
 		}
 	}
 
+	// Composite values replaced by a different type, or by nil, have changed
+	if reflect.TypeOf(prevValue) != reflect.TypeOf(latestValue) {
+		return true, prevValue, latestValue, nil
+	}
+
 	// No changes detected
 	return false, nil, nil, nil
 }
 
+// comparison is the result of comparing one pair of values.
+type comparison struct {
+	changed bool
+	before  any
+	after   any
+	mask    *UpdateMask
+	skipped bool // the field is filtered out
+	removed bool // the field is missing from the latest object
+}
+
+// parallel calls fn for every index below n. With WithParallelism, indices are split into chunks
+// of the given size that run on the bounded worker pool, or on the calling goroutine when every
+// worker is busy. It stops calling fn once the context is done.
+func (d differ) parallel(n, chunk int, fn func(i int)) {
+	if d.opts == nil || d.opts.slots == nil || n <= chunk {
+		for i := 0; i < n; i++ {
+			if d.opts != nil && d.opts.cancelled() {
+				return
+			}
+			fn(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := min(start+chunk, n)
+		run := func() {
+			for i := start; i < end; i++ {
+				if d.opts.cancelled() {
+					return
+				}
+				fn(i)
+			}
+		}
+
+		select {
+		case d.opts.slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-d.opts.slots
+					wg.Done()
+				}()
+				run()
+			}()
+		default:
+			run()
+		}
+	}
+	wg.Wait()
+}
+
 // Helper function to calculate max of two integers
 func max(a, b int) int {
 	if a > b {
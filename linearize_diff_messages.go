@@ -0,0 +1,183 @@
+package linearize
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// diffMessages compares two messages field by field like diff, walking both through protoreflect
+// and linearizing only the values that appear in the result.
+func (d differ) diffMessages(previous, latest protoreflect.Message) (before LinearizedObject, after LinearizedObject, mask *UpdateMask, err error) {
+	changed, before, after, mask := d.compareMessages(previous, latest, false)
+	if !changed {
+		return nil, nil, nil, nil
+	}
+	return before, after, mask, nil
+}
+
+// compareMessages compares two messages like compare compares two objects. When nested is false
+// only changed fields are kept, as diff does for the top-level objects.
+func (d differ) compareMessages(previous, latest protoreflect.Message, nested bool) (changed bool, before, after LinearizedObject, mask *UpdateMask) {
+	before = make(LinearizedObject)
+	after = make(LinearizedObject)
+	mask = &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+
+	previous.Range(func(fd protoreflect.FieldDescriptor, prevValue protoreflect.Value) bool {
+		key := int32(fd.Number())
+		child, ok := d.field(key)
+		if !ok {
+			// Filtered fields are carried over unchanged, except those Linearize leaves out
+			if nested && !fieldOptions(fd).GetDiffIgnore() {
+				before[key] = d.linearizeField(fd, prevValue)
+				if latest.Has(fd) {
+					after[key] = d.linearizeField(fd, latest.Get(fd))
+				}
+			}
+			return true
+		}
+		if !latest.Has(fd) {
+			before[key] = d.linearizeField(fd, prevValue)
+			after[key] = nil
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE}
+			changed = true
+			return true
+		}
+
+		latestValue := latest.Get(fd)
+		fieldChanged, fieldBefore, fieldAfter, fieldMask := child.compareFields(fd, prevValue, latestValue)
+		if fieldChanged {
+			before[key] = fieldBefore
+			after[key] = fieldAfter
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: fieldMask}
+			changed = true
+		} else if nested {
+			before[key] = d.linearizeField(fd, prevValue)
+			after[key] = d.linearizeField(fd, latestValue)
+		}
+		return true
+	})
+
+	latest.Range(func(fd protoreflect.FieldDescriptor, latestValue protoreflect.Value) bool {
+		key := int32(fd.Number())
+		if previous.Has(fd) {
+			return true
+		}
+		if _, ok := d.field(key); !ok {
+			if nested && !fieldOptions(fd).GetDiffIgnore() {
+				after[key] = d.linearizeField(fd, latestValue)
+			}
+			return true
+		}
+		before[key] = nil
+		after[key] = d.linearizeField(fd, latestValue)
+		mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_ADD}
+		changed = true
+		return true
+	})
+
+	return changed, before, after, mask
+}
+
+// compareFields compares the values of a field present in both messages like compare. Nested
+// messages and lists of messages are walked in lockstep, other values are linearized and compared.
+func (d differ) compareFields(fd protoreflect.FieldDescriptor, prevValue, latestValue protoreflect.Value) (changed bool, before, after any, mask *UpdateMask) {
+	if d.skipsEqual() && prevValue.Equal(latestValue) {
+		return false, nil, nil, nil
+	}
+
+	switch {
+	case fd.IsMap() || fieldOptions(fd).GetAtomic():
+	case fd.IsList():
+		if fd.Message() != nil && d.opts.listStrategy != ListReplace && fieldOptions(fd).GetListKey() == "" {
+			return d.compareLists(fd, prevValue.List(), latestValue.List())
+		}
+	case fd.Message() != nil:
+		return d.compareMessages(prevValue.Message(), latestValue.Message(), true)
+	}
+	return d.compare(d.linearizeField(fd, prevValue), d.linearizeField(fd, latestValue), nil, nil)
+}
+
+// compareLists compares two lists of messages by position like compare compares two slices.
+func (d differ) compareLists(fd protoreflect.FieldDescriptor, prev, latest protoreflect.List) (changed bool, before, after any, mask *UpdateMask) {
+	mask = &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+	prevLen := prev.Len()
+	latestLen := latest.Len()
+
+	maxLen := max(prevLen, latestLen)
+	mergedBefore := make(LinearizedSlice, maxLen)
+	mergedAfter := make(LinearizedSlice, maxLen)
+
+	for i := 0; i < maxLen; i++ {
+		key := int32(i)
+
+		var elemChanged bool
+		var elemBefore, elemAfter any
+		var elemMask *UpdateMask
+		switch {
+		case i >= prevLen:
+			elemChanged, elemAfter = true, d.linearizeElement(latest.Get(i))
+		case i >= latestLen:
+			elemChanged, elemBefore = true, d.linearizeElement(prev.Get(i))
+		case d.skipsEqual() && prev.Get(i).Equal(latest.Get(i)):
+			elemBefore = d.linearizeElement(prev.Get(i))
+		default:
+			elemChanged, elemBefore, elemAfter, elemMask = d.compareMessages(prev.Get(i).Message(), latest.Get(i).Message(), true)
+		}
+
+		mergedBefore[key] = elemBefore
+		if elemChanged {
+			changed = true
+
+			if latestLen < prevLen && i >= prevLen-1 {
+				mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE}
+				continue
+			}
+			mergedAfter[key] = elemAfter
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: elemMask}
+		}
+	}
+
+	return changed, mergedBefore, mergedAfter, mask
+}
+
+// skipsEqual reports whether identical values can be skipped without comparing them. They cannot
+// when every field must be visited to enforce the depth limit, or when signed zeros are distinguished.
+func (d differ) skipsEqual() bool {
+	if d.opts.maxDepth > 0 || d.opts.tolerance.SignedZeros {
+		return false
+	}
+	for _, tolerance := range d.opts.fieldTolerances {
+		if tolerance.SignedZeros {
+			return false
+		}
+	}
+	return true
+}
+
+// linearizeField linearizes a field value the way Linearize does without options.
+func (d differ) linearizeField(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	var plain linearizeOptions
+	md := fd.Message()
+	if fd.IsMap() {
+		md = fd.MapValue().Message()
+	}
+	var scope fieldScope
+	if md != nil {
+		scope = newFieldScope(descriptorFilters(md))
+	}
+	linearized, err := plain.linearizeField(fd, value, scope, d.depth)
+	if err != nil {
+		d.opts.fail(err)
+	}
+	return linearized
+}
+
+// linearizeElement linearizes a message element of a list.
+func (d differ) linearizeElement(elem protoreflect.Value) LinearizedObject {
+	var plain linearizeOptions
+	msg := elem.Message()
+	linearized, err := plain.linearize(msg.Interface(), newFieldScope(descriptorFilters(msg.Descriptor())), d.depth)
+	if err != nil {
+		d.opts.fail(err)
+	}
+	return linearized
+}
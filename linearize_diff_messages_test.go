@@ -0,0 +1,177 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// diffLinearized diffs two messages through Linearize and Diff, as DiffMessages did before walking them in lockstep.
+func diffLinearized(t testing.TB, previous, latest proto.Message, opts ...DiffOption) (Patch, error) {
+	before, err := Linearize(previous)
+	require.NoError(t, err)
+	after, err := Linearize(latest)
+	require.NoError(t, err)
+
+	md := latest.ProtoReflect().Descriptor()
+	before, after, mask, err := Diff(before, after, append([]DiffOption{WithDescriptor(md)}, opts...)...)
+	return Patch{Mask: mask, Before: before, After: after}, err
+}
+
+func TestDiffMessages(t *testing.T) {
+	changes := map[string]func(msg *mocks.SuperComplex){
+		"no change":       func(msg *mocks.SuperComplex) {},
+		"scalar":          func(msg *mocks.SuperComplex) { msg.Field1 = "changed" },
+		"nested scalar":   func(msg *mocks.SuperComplex) { msg.Nested.Nested.Field2 = 7 },
+		"removed field":   func(msg *mocks.SuperComplex) { msg.Field2 = 0 },
+		"cleared message": func(msg *mocks.SuperComplex) { msg.Nested.Nested = nil },
+		"removed message": func(msg *mocks.SuperComplex) {
+			msg.Nested = nil
+		},
+		"appended element": func(msg *mocks.SuperComplex) {
+			msg.Repeated = append(msg.Repeated, &mocks.Complex{Field1: "appended"})
+		},
+		"removed elements": func(msg *mocks.SuperComplex) {
+			msg.Repeated = msg.Repeated[:1]
+		},
+		"changed element": func(msg *mocks.SuperComplex) {
+			msg.Repeated[0].Nested.Field1 = "changed"
+		},
+		"removed scalar element": func(msg *mocks.SuperComplex) {
+			msg.Nested.Nested.Repeated = msg.Nested.Nested.Repeated[:1]
+		},
+		"changed map entry": func(msg *mocks.SuperComplex) {
+			msg.Map[2].Field2 = 1
+		},
+		"added map entry": func(msg *mocks.SuperComplex) {
+			msg.Nested.Map["key0"] = &mocks.Simple{Field1: "first"}
+		},
+	}
+
+	for name, change := range changes {
+		t.Run("should match linearized diff for "+name, func(t *testing.T) {
+			// Arrange
+			previous := mocks.CreateSuperComplexMessage()
+			previous.Repeated = append(previous.Repeated, mocks.CreateComplexMessage(), mocks.CreateComplexMessage())
+			latest := proto.Clone(previous).(*mocks.SuperComplex)
+			change(latest)
+			expected, err := diffLinearized(t, previous, latest)
+			require.NoError(t, err)
+
+			// Act
+			patch, err := DiffMessages(previous, latest)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(expected.Mask, patch.Mask), "mask %v, expected %v", patch.Mask, expected.Mask)
+			assert.Equal(t, expected.Before, patch.Before)
+			assert.Equal(t, expected.After, patch.After)
+		})
+	}
+
+	options := map[string][]DiffOption{
+		"list replace": {WithListStrategy(ListReplace)},
+		"redaction":    {WithRedaction((&mocks.Annotated{}).ProtoReflect().Descriptor(), []byte("salt"))},
+		"tolerance":    {WithFloatTolerance(FloatTolerance{Absolute: 1})},
+		"signed zeros": {WithFloatTolerance(FloatTolerance{SignedZeros: true})},
+	}
+
+	for name, opts := range options {
+		t.Run("should match linearized diff with "+name, func(t *testing.T) {
+			// Arrange
+			previous := &mocks.Annotated{
+				Name:        "name",
+				UpdatedAt:   1,
+				Address:     mocks.CreateSimpleMessage(),
+				Items:       []*mocks.Keyed{{Id: "a", Value: "1"}, {Id: "b", Value: "2"}},
+				Temperature: 20,
+				Secret:      "secret",
+			}
+			latest := proto.Clone(previous).(*mocks.Annotated)
+			latest.UpdatedAt = 2
+			latest.Address.Field2 = 43
+			latest.Items[1] = &mocks.Keyed{Id: "c", Value: "2"}
+			latest.Temperature = 20.5
+			latest.Secret = "changed"
+			expected, err := diffLinearized(t, previous, latest, opts...)
+			require.NoError(t, err)
+
+			// Act
+			patch, err := DiffMessages(previous, latest, opts...)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(expected.Mask, patch.Mask), "mask %v, expected %v", patch.Mask, expected.Mask)
+			assert.Equal(t, expected.Before, patch.Before)
+			assert.Equal(t, expected.After, patch.After)
+		})
+	}
+
+	t.Run("should diff messages typed as proto.Message", func(t *testing.T) {
+		// Arrange
+		var previous, latest proto.Message = mocks.CreateSuperComplexMessage(), mocks.CreateSuperComplexMessage()
+		latest.(*mocks.SuperComplex).Field1 = "changed"
+		expected, err := diffLinearized(t, previous, latest)
+		require.NoError(t, err)
+
+		// Act
+		patch, err := DiffMessages(previous, latest)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected.Mask, patch.Mask), "mask %v, expected %v", patch.Mask, expected.Mask)
+		assert.Equal(t, expected.After, patch.After)
+	})
+
+	t.Run("should reject messages of different types", func(t *testing.T) {
+		// Arrange
+		var previous, latest proto.Message = mocks.CreateSuperComplexMessage(), mocks.CreateSimpleMessage()
+
+		// Act
+		_, err := DiffMessages(previous, latest)
+
+		// Assert
+		assert.ErrorContains(t, err, "cannot diff")
+	})
+
+	t.Run("should fail when messages are nested too deeply", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+
+		// Act
+		_, err := DiffMessages(previous, latest, WithMaxDepth(2))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrDepthExceeded)
+	})
+}
+
+func BenchmarkDiffLinearized(b *testing.B) {
+	previous := largeMessage()
+	latest := largeMessage()
+	latest.Repeated[500].Field2 = 1
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := diffLinearized(b, previous, latest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDiffMessages(b *testing.B) {
+	previous := largeMessage()
+	latest := largeMessage()
+	latest.Repeated[500].Field2 = 1
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := DiffMessages(previous, latest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
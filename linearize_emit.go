@@ -0,0 +1,524 @@
+package linearize
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrUnbalanced is returned by the built-in emitters when events do not nest properly.
+var ErrUnbalanced = errors.New("unbalanced emitter events")
+
+// Emitter receives a linearized object as a stream of events. Values are identified by field like
+// in a LinearizedObject: by field number inside objects, index inside slices and position inside maps.
+// The top-level object is emitted at field 0. Fields, elements and entries are emitted in ascending order.
+type Emitter interface {
+	// BeginObject starts an object holding size fields. It is ended by End.
+	BeginObject(field int32, size int) error
+	// BeginSlice starts a slice holding size elements. It is ended by End.
+	BeginSlice(field int32, size int) error
+	// BeginMap starts a map holding size entries. It is ended by End.
+	BeginMap(field int32, size int) error
+	// MapEntry starts the entry of the current map at a position. Its value follows at the same position.
+	MapEntry(field int32, key any) error
+	// Scalar emits a value that is not an object, slice or map.
+	Scalar(field int32, value any) error
+	// End closes the most recently started object, slice or map.
+	End() error
+}
+
+// LinearizeTo flattens a message like Linearize in a single pass, sending it to the emitter instead of
+// building a LinearizedObject. It returns the first error returned by the emitter.
+func LinearizeTo(message proto.Message, emitter Emitter, opts ...LinearizeOption) error {
+	return LinearizeToContext(context.Background(), message, emitter, opts...)
+}
+
+// LinearizeToContext streams a message like LinearizeTo, returning the context's error once it is done.
+func LinearizeToContext(ctx context.Context, message proto.Message, emitter Emitter, opts ...LinearizeOption) error {
+	options := linearizeOptions{limiter: limiter{ctx: ctx}, filters: descriptorFilters(message.ProtoReflect().Descriptor())}
+	for _, opt := range opts {
+		opt.applyLinearize(&options)
+	}
+	return options.emit(0, message, newFieldScope(options.filters), 1, emitter)
+}
+
+// Emit sends an existing object to the emitter.
+func Emit(obj LinearizedObject, emitter Emitter) error {
+	return emitValue(0, obj, emitter)
+}
+
+// fieldValue is a populated field of a message being emitted.
+type fieldValue struct {
+	fd     protoreflect.FieldDescriptor
+	value  protoreflect.Value
+	nested fieldScope
+}
+
+// fieldValuePool reuses the buffers the fields of a message are sorted in.
+var fieldValuePool = sync.Pool{
+	New: func() any { return new([]fieldValue) },
+}
+
+// emit streams a message at the given depth, keeping only the fields in scope.
+func (o *linearizeOptions) emit(field int32, message proto.Message, scope fieldScope, depth int, e Emitter) error {
+	if o.maxDepth > 0 && depth > o.maxDepth {
+		return fmt.Errorf("%w: %d", ErrDepthExceeded, o.maxDepth)
+	}
+	if err := o.limiter.enter(); err != nil {
+		return err
+	}
+	if message == nil {
+		if err := e.BeginObject(field, 0); err != nil {
+			return err
+		}
+		return e.End()
+	}
+
+	if packed, ok, err := o.unpackAny(message.ProtoReflect()); err != nil {
+		return err
+	} else if ok {
+		return o.emitAny(field, message.ProtoReflect(), packed, scope, depth, e)
+	}
+
+	// Collect the fields in scope into a pooled buffer to emit them in field order
+	buffer := fieldValuePool.Get().(*[]fieldValue)
+	fields := (*buffer)[:0]
+	message.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if nested, ok := scope.field(int32(fd.Number())); ok {
+			fields = append(fields, fieldValue{fd: fd, value: value, nested: nested})
+		}
+		return true
+	})
+	defer func() {
+		clear(fields)
+		*buffer = fields[:0]
+		fieldValuePool.Put(buffer)
+	}()
+	slices.SortFunc(fields, func(a, b fieldValue) int {
+		return cmp.Compare(a.fd.Number(), b.fd.Number())
+	})
+
+	if err := e.BeginObject(field, len(fields)); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := o.limiter.add(1); err != nil {
+			return err
+		}
+		if err := o.emitField(f, depth, e); err != nil {
+			return err
+		}
+	}
+	return e.End()
+}
+
+// emitField streams the value of a field of a message at the given depth.
+func (o *linearizeOptions) emitField(f fieldValue, depth int, e Emitter) error {
+	key := int32(f.fd.Number())
+	isMessage := f.fd.Kind() == protoreflect.MessageKind || f.fd.Kind() == protoreflect.GroupKind
+
+	switch {
+	case f.fd.IsMap():
+		m := f.value.Map()
+		if err := o.limiter.mapping(m.Len()); err != nil {
+			return err
+		}
+
+		// Collect entries into a pooled buffer to sort them in the configured order
+		buffer := mapEntryPool.Get().(*[]mapEntry)
+		entries := (*buffer)[:0]
+		m.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries = append(entries, mapEntry{key: k, value: v})
+			return true
+		})
+		defer func() {
+			clear(entries)
+			*buffer = entries[:0]
+			mapEntryPool.Put(buffer)
+		}()
+		o.mapKeyOrder.sort(entries)
+
+		if err := e.BeginMap(key, len(entries)); err != nil {
+			return err
+		}
+		for i, entry := range entries {
+			position := int32(i)
+			if err := e.MapEntry(position, entry.key.Interface()); err != nil {
+				return err
+			}
+			var err error
+			if f.fd.MapValue().Message() != nil {
+				err = o.emit(position, entry.value.Message().Interface(), f.nested, depth+1, e)
+			} else {
+				err = e.Scalar(position, entry.value.Interface())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return e.End()
+
+	case f.fd.IsList():
+		list := f.value.List()
+		if err := o.limiter.list(list.Len()); err != nil {
+			return err
+		}
+		if err := e.BeginSlice(key, list.Len()); err != nil {
+			return err
+		}
+		for i := 0; i < list.Len(); i++ {
+			var err error
+			if isMessage {
+				err = o.emit(int32(i), list.Get(i).Message().Interface(), f.nested, depth+1, e)
+			} else {
+				err = e.Scalar(int32(i), list.Get(i).Interface())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return e.End()
+
+	case isMessage:
+		return o.emit(key, f.value.Message().Interface(), f.nested, depth+1, e)
+	}
+	return e.Scalar(key, f.value.Interface())
+}
+
+// emitValue streams a linearized value held at field.
+func emitValue(field int32, value any, e Emitter) error {
+	var err error
+	switch v := value.(type) {
+	case LinearizedObject:
+		if err = e.BeginObject(field, len(v)); err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(v) {
+			if err = emitValue(key, v[key], e); err != nil {
+				return err
+			}
+		}
+	case LinearizedSlice:
+		if err = e.BeginSlice(field, len(v)); err != nil {
+			return err
+		}
+		for _, index := range sortedKeys(v) {
+			if err = emitValue(index, v[index], e); err != nil {
+				return err
+			}
+		}
+	case LinearizedMap:
+		if err = e.BeginMap(field, len(v)); err != nil {
+			return err
+		}
+		for _, position := range sortedKeys(v) {
+			if err = e.MapEntry(position, v[position][0]); err != nil {
+				return err
+			}
+			if err = emitValue(position, v[position][1], e); err != nil {
+				return err
+			}
+		}
+	default:
+		return e.Scalar(field, value)
+	}
+	return e.End()
+}
+
+// ObjectEmitter builds a LinearizedObject from the events it receives.
+type ObjectEmitter struct {
+	stack []objectFrame
+	obj   LinearizedObject
+}
+
+// objectFrame is a container being built and the key of its current map entry.
+type objectFrame struct {
+	container any
+	key       any
+}
+
+// NewObjectEmitter returns an emitter building a LinearizedObject in memory.
+func NewObjectEmitter() *ObjectEmitter {
+	return &ObjectEmitter{}
+}
+
+// Object returns the object once the top-level object has ended, or nil before.
+func (e *ObjectEmitter) Object() LinearizedObject {
+	if len(e.stack) > 0 {
+		return nil
+	}
+	return e.obj
+}
+
+func (e *ObjectEmitter) BeginObject(field int32, size int) error {
+	return e.begin(field, newObject(size))
+}
+
+func (e *ObjectEmitter) BeginSlice(field int32, size int) error {
+	return e.begin(field, newSlice(size))
+}
+
+func (e *ObjectEmitter) BeginMap(field int32, size int) error {
+	return e.begin(field, newMap(size))
+}
+
+func (e *ObjectEmitter) MapEntry(field int32, key any) error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: map entry outside a map", ErrUnbalanced)
+	}
+	e.stack[len(e.stack)-1].key = key
+	return nil
+}
+
+func (e *ObjectEmitter) Scalar(field int32, value any) error {
+	return e.set(field, value)
+}
+
+func (e *ObjectEmitter) End() error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: end without begin", ErrUnbalanced)
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	return nil
+}
+
+// begin stores a new container and makes it the current one.
+func (e *ObjectEmitter) begin(field int32, container any) error {
+	if len(e.stack) == 0 {
+		obj, ok := container.(LinearizedObject)
+		if !ok {
+			return fmt.Errorf("%w: expected a top-level object but got %T", ErrUnbalanced, container)
+		}
+		e.obj = obj
+	} else if err := e.set(field, container); err != nil {
+		return err
+	}
+	e.stack = append(e.stack, objectFrame{container: container})
+	return nil
+}
+
+// set stores a value in the current container.
+func (e *ObjectEmitter) set(field int32, value any) error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: value outside an object", ErrUnbalanced)
+	}
+	frame := e.stack[len(e.stack)-1]
+	switch c := frame.container.(type) {
+	case LinearizedObject:
+		c[field] = value
+	case LinearizedSlice:
+		c[field] = value
+	case LinearizedMap:
+		c[field] = [2]any{frame.key, value}
+	}
+	return nil
+}
+
+// RowEmitter calls a function for every leaf it receives with the path of the leaf. Leaves are
+// scalars and empty objects, slices and maps, so the rows describe the whole object.
+type RowEmitter struct {
+	write func(path Path, value any) error
+	stack []rowFrame
+}
+
+// rowFrame is an open container, its path and the key of its current map entry.
+type rowFrame struct {
+	path  Path
+	value any // empty container reported when nothing is emitted inside it
+	kind  PathElementKind
+	key   any
+	empty bool
+}
+
+// NewRowEmitter returns an emitter calling write for every leaf.
+func NewRowEmitter(write func(path Path, value any) error) *RowEmitter {
+	return &RowEmitter{write: write}
+}
+
+func (e *RowEmitter) BeginObject(field int32, size int) error {
+	return e.begin(field, LinearizedObject{}, FieldElement)
+}
+
+func (e *RowEmitter) BeginSlice(field int32, size int) error {
+	return e.begin(field, LinearizedSlice{}, IndexElement)
+}
+
+func (e *RowEmitter) BeginMap(field int32, size int) error {
+	return e.begin(field, LinearizedMap{}, KeyElement)
+}
+
+func (e *RowEmitter) MapEntry(field int32, key any) error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: map entry outside a map", ErrUnbalanced)
+	}
+	e.stack[len(e.stack)-1].key = key
+	return nil
+}
+
+func (e *RowEmitter) Scalar(field int32, value any) error {
+	path, err := e.child(field)
+	if err != nil {
+		return err
+	}
+	return e.write(path, value)
+}
+
+func (e *RowEmitter) End() error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: end without begin", ErrUnbalanced)
+	}
+	frame := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	if frame.empty && len(frame.path) > 0 {
+		return e.write(frame.path, frame.value)
+	}
+	return nil
+}
+
+// begin opens a container. The top-level object has an empty path.
+func (e *RowEmitter) begin(field int32, value any, kind PathElementKind) error {
+	var path Path
+	if len(e.stack) > 0 {
+		var err error
+		if path, err = e.child(field); err != nil {
+			return err
+		}
+	}
+	e.stack = append(e.stack, rowFrame{path: path, value: value, kind: kind, empty: true})
+	return nil
+}
+
+// child returns the path of a value in the current container.
+func (e *RowEmitter) child(field int32) (Path, error) {
+	if len(e.stack) == 0 {
+		return nil, fmt.Errorf("%w: value outside an object", ErrUnbalanced)
+	}
+	frame := &e.stack[len(e.stack)-1]
+	frame.empty = false
+	switch frame.kind {
+	case IndexElement:
+		return frame.path.Index(field), nil
+	case KeyElement:
+		return frame.path.Key(frame.key), nil
+	}
+	return frame.path.Field(field), nil
+}
+
+// codecFlushSize is the amount of encoded data CodecEmitter buffers before writing it.
+const codecFlushSize = 32 << 10
+
+// CodecEmitter writes the events it receives in the binary form produced by MarshalObject.
+type CodecEmitter struct {
+	w     io.Writer
+	buf   []byte
+	stack []codecFrame
+}
+
+// codecFrame is an open container, the number of values it announced and the number written.
+type codecFrame struct {
+	tag     byte
+	size    int
+	written int
+}
+
+// NewCodecEmitter returns an emitter encoding the object to w. The data is written in chunks and
+// completely once the top-level object ends. It can be read back with UnmarshalObject.
+func NewCodecEmitter(w io.Writer) *CodecEmitter {
+	return &CodecEmitter{w: w}
+}
+
+func (e *CodecEmitter) BeginObject(field int32, size int) error {
+	return e.begin(field, tagObject, size)
+}
+
+func (e *CodecEmitter) BeginSlice(field int32, size int) error {
+	return e.begin(field, tagSlice, size)
+}
+
+func (e *CodecEmitter) BeginMap(field int32, size int) error {
+	return e.begin(field, tagMap, size)
+}
+
+func (e *CodecEmitter) MapEntry(field int32, key any) error {
+	if len(e.stack) == 0 || e.stack[len(e.stack)-1].tag != tagMap {
+		return fmt.Errorf("%w: map entry outside a map", ErrUnbalanced)
+	}
+	e.stack[len(e.stack)-1].written++
+	e.buf = protowire.AppendVarint(e.buf, protowire.EncodeZigZag(int64(field)))
+	var err error
+	e.buf, err = appendValue(e.buf, key)
+	return err
+}
+
+func (e *CodecEmitter) Scalar(field int32, value any) error {
+	if err := e.key(field); err != nil {
+		return err
+	}
+	var err error
+	e.buf, err = appendValue(e.buf, value)
+	if err != nil {
+		return err
+	}
+	return e.flush(codecFlushSize)
+}
+
+func (e *CodecEmitter) End() error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: end without begin", ErrUnbalanced)
+	}
+	frame := e.stack[len(e.stack)-1]
+	if frame.written != frame.size {
+		return fmt.Errorf("%w: %d values written but %d announced", ErrUnbalanced, frame.written, frame.size)
+	}
+	e.stack = e.stack[:len(e.stack)-1]
+	if len(e.stack) == 0 {
+		return e.flush(0)
+	}
+	return e.flush(codecFlushSize)
+}
+
+// begin writes the header of a container.
+func (e *CodecEmitter) begin(field int32, tag byte, size int) error {
+	if len(e.stack) == 0 {
+		if tag != tagObject {
+			return fmt.Errorf("%w: expected a top-level object", ErrUnbalanced)
+		}
+	} else if err := e.key(field); err != nil {
+		return err
+	}
+	e.buf = protowire.AppendVarint(append(e.buf, tag), uint64(size))
+	e.stack = append(e.stack, codecFrame{tag: tag, size: size})
+	return nil
+}
+
+// key writes the key of a value in the current object or slice. Map keys are written by MapEntry.
+func (e *CodecEmitter) key(field int32) error {
+	if len(e.stack) == 0 {
+		return fmt.Errorf("%w: value outside an object", ErrUnbalanced)
+	}
+	frame := &e.stack[len(e.stack)-1]
+	if frame.tag == tagMap {
+		return nil
+	}
+	frame.written++
+	e.buf = protowire.AppendVarint(e.buf, protowire.EncodeZigZag(int64(field)))
+	return nil
+}
+
+// flush writes the buffered data once it reaches size bytes.
+func (e *CodecEmitter) flush(size int) error {
+	if len(e.buf) < size || len(e.buf) == 0 {
+		return nil
+	}
+	_, err := e.w.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
@@ -0,0 +1,211 @@
+package linearize
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLinearizeTo(t *testing.T) {
+	messages := map[string]proto.Message{
+		"simple":        mocks.CreateSimpleMessage(),
+		"complex":       mocks.CreateComplexMessage(),
+		"super complex": mocks.CreateSuperComplexMessage(),
+		"empty":         &mocks.SuperComplex{Nested: &mocks.Complex{}},
+		"large":         largeMessage(),
+	}
+
+	for name, msg := range messages {
+		t.Run("should build the same object as Linearize for "+name, func(t *testing.T) {
+			// Arrange
+			expected, err := Linearize(msg)
+			require.NoError(t, err)
+			emitter := NewObjectEmitter()
+
+			// Act
+			err = LinearizeTo(msg, emitter)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, emitter.Object())
+		})
+
+		t.Run("should encode the same bytes as MarshalObject for "+name, func(t *testing.T) {
+			// Arrange
+			obj, err := Linearize(msg)
+			require.NoError(t, err)
+			expected, err := MarshalObject(obj)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+
+			// Act
+			err = LinearizeTo(msg, NewCodecEmitter(&buf))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, buf.Bytes())
+		})
+	}
+
+	t.Run("should honor linearize options", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		md := msg.ProtoReflect().Descriptor()
+		filter, err := IncludeFields(md, "Field1", "Nested.Map")
+		require.NoError(t, err)
+		opts := []LinearizeOption{filter, WithMapKeyOrder(MapKeysNatural)}
+		expected, err := Linearize(msg, opts...)
+		require.NoError(t, err)
+		emitter := NewObjectEmitter()
+
+		// Act
+		err = LinearizeTo(msg, emitter, opts...)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, emitter.Object())
+	})
+
+	t.Run("should enforce limits", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		sliceErr := LinearizeTo(msg, NewObjectEmitter(), WithLimits(Limits{MaxSliceLength: 1}))
+		depthErr := LinearizeTo(msg, NewObjectEmitter(), WithLimits(Limits{MaxDepth: 2}))
+		_, contextErr := LinearizeContext(ctx, msg)
+
+		// Assert
+		assert.ErrorIs(t, sliceErr, ErrTooLarge)
+		assert.ErrorIs(t, depthErr, ErrDepthExceeded)
+		assert.ErrorIs(t, contextErr, context.Canceled)
+	})
+
+	t.Run("should stop streaming once the context is canceled", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		complete := &cancelingEmitter{Emitter: NewObjectEmitter(), cancel: func() {}}
+		require.NoError(t, LinearizeToContext(context.Background(), msg, complete))
+		ctx, cancel := context.WithCancel(context.Background())
+		emitter := &cancelingEmitter{Emitter: NewObjectEmitter(), cancel: cancel}
+
+		// Act
+		err := LinearizeToContext(ctx, msg, emitter)
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, emitter.scalars, complete.scalars)
+	})
+}
+
+// cancelingEmitter cancels a context on the first scalar it receives.
+type cancelingEmitter struct {
+	Emitter
+	cancel  context.CancelFunc
+	scalars int
+}
+
+func (e *cancelingEmitter) Scalar(field int32, value any) error {
+	e.scalars++
+	e.cancel()
+	return e.Emitter.Scalar(field, value)
+}
+
+func TestEmit(t *testing.T) {
+	t.Run("should write a row for every leaf", func(t *testing.T) {
+		// Arrange
+		obj := LinearizedObject{
+			1: "value",
+			2: LinearizedSlice{0: int32(1), 1: int32(2)},
+			3: LinearizedMap{0: {"key", LinearizedObject{1: true}}},
+			4: LinearizedObject{},
+			5: LinearizedSlice{},
+			6: nil,
+		}
+		rows := make(map[string]any)
+		emitter := NewRowEmitter(func(path Path, value any) error {
+			rows[path.String()] = value
+			return nil
+		})
+
+		// Act
+		err := Emit(obj, emitter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"1":          "value",
+			"2[0]":       int32(1),
+			"2[1]":       int32(2),
+			`3["key"].1`: true,
+			"4":          LinearizedObject{},
+			"5":          LinearizedSlice{},
+			"6":          nil,
+		}, rows)
+	})
+
+	t.Run("should rebuild the object", func(t *testing.T) {
+		// Arrange
+		obj, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		emitter := NewObjectEmitter()
+
+		// Act
+		err = Emit(obj, emitter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, obj, emitter.Object())
+	})
+
+	t.Run("should reject unbalanced events", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		emitter := NewCodecEmitter(&buf)
+		require.NoError(t, emitter.BeginObject(0, 2))
+		require.NoError(t, emitter.Scalar(1, "value"))
+
+		// Act
+		err := emitter.End()
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnbalanced)
+		assert.ErrorIs(t, NewObjectEmitter().End(), ErrUnbalanced)
+		assert.ErrorIs(t, NewRowEmitter(nil).Scalar(1, "value"), ErrUnbalanced)
+	})
+}
+
+func BenchmarkLinearizeTo(b *testing.B) {
+	msg := largeMessage()
+
+	b.Run("Linearize+MarshalObject", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			obj, err := Linearize(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := MarshalObject(obj); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("CodecEmitter", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf bytes.Buffer
+		for i := 0; i < b.N; i++ {
+			buf.Reset()
+			if err := LinearizeTo(msg, NewCodecEmitter(&buf)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,296 @@
+package linearize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Equal reports whether two objects hold the same values. Integers compare by value
+// regardless of width, NaN equals NaN and map entries compare by key regardless of position.
+func (o LinearizedObject) Equal(other LinearizedObject) bool {
+	return equalValues(o, other)
+}
+
+// Equal reports whether two slices hold the same elements in the same order.
+func (s LinearizedSlice) Equal(other LinearizedSlice) bool {
+	return equalValues(s, other)
+}
+
+// Equal reports whether two maps hold the same entries.
+func (m LinearizedMap) Equal(other LinearizedMap) bool {
+	return equalValues(m, other)
+}
+
+// Clone returns a deep copy of the object.
+func (o LinearizedObject) Clone() LinearizedObject {
+	if o == nil {
+		return nil
+	}
+	return cloneValue(o).(LinearizedObject)
+}
+
+// Clone returns a deep copy of the slice.
+func (s LinearizedSlice) Clone() LinearizedSlice {
+	if s == nil {
+		return nil
+	}
+	return cloneValue(s).(LinearizedSlice)
+}
+
+// Clone returns a deep copy of the map.
+func (m LinearizedMap) Clone() LinearizedMap {
+	if m == nil {
+		return nil
+	}
+	return cloneValue(m).(LinearizedMap)
+}
+
+// Hash returns the SHA-256 root of the object's Merkle tree.
+// Objects that are Equal always have the same hash.
+func (o LinearizedObject) Hash() [32]byte {
+	return hashValue(o)
+}
+
+// Hash returns the SHA-256 root of the slice's Merkle tree.
+func (s LinearizedSlice) Hash() [32]byte {
+	return hashValue(s)
+}
+
+// Hash returns the SHA-256 root of the map's Merkle tree.
+func (m LinearizedMap) Hash() [32]byte {
+	return hashValue(m)
+}
+
+// equalValues compares two linearized values structurally.
+func equalValues(a, b any) bool {
+	switch av := a.(type) {
+	case nil:
+		return b == nil
+	case LinearizedObject:
+		bv, ok := b.(LinearizedObject)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, exists := bv[key]
+			if !exists || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	case LinearizedSlice:
+		bv, ok := b.(LinearizedSlice)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, exists := bv[key]
+			if !exists || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	case LinearizedMap:
+		bv, ok := b.(LinearizedMap)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		entries := make(map[string]any, len(bv))
+		for _, entry := range bv {
+			entries[string(canonicalBytes(entry[0]))] = entry[1]
+		}
+		for _, entry := range av {
+			other, exists := entries[string(canonicalBytes(entry[0]))]
+			if !exists || !equalValues(entry[1], other) {
+				return false
+			}
+		}
+		return true
+	case [2]any:
+		bv, ok := b.([2]any)
+		return ok && equalValues(av[0], bv[0]) && equalValues(av[1], bv[1])
+	}
+	return equalScalars(a, b)
+}
+
+// equalScalars compares two leaf values.
+func equalScalars(a, b any) bool {
+	if ai, ok := signedValue(a); ok {
+		if bi, ok := signedValue(b); ok {
+			return ai == bi
+		}
+		bu, ok := unsignedValue(b)
+		return ok && ai >= 0 && uint64(ai) == bu
+	}
+	if au, ok := unsignedValue(a); ok {
+		if bu, ok := unsignedValue(b); ok {
+			return au == bu
+		}
+		bi, ok := signedValue(b)
+		return ok && bi >= 0 && uint64(bi) == au
+	}
+	if af, ok := floatValue(a); ok {
+		bf, ok := floatValue(b)
+		return ok && (af == bf || (math.IsNaN(af) && math.IsNaN(bf)))
+	}
+
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case []byte:
+		bv, ok := b.([]byte)
+		return ok && bytes.Equal(av, bv)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func signedValue(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case protoreflect.EnumNumber:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func unsignedValue(v any) (uint64, bool) {
+	switch n := v.(type) {
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case uint:
+		return uint64(n), true
+	}
+	return 0, false
+}
+
+func floatValue(v any) (float64, bool) {
+	switch f := v.(type) {
+	case float32:
+		return float64(f), true
+	case float64:
+		return f, true
+	}
+	return 0, false
+}
+
+// cloneValue deep copies a linearized value.
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case LinearizedObject:
+		c := make(LinearizedObject, len(v))
+		for key, elem := range v {
+			c[key] = cloneValue(elem)
+		}
+		return c
+	case LinearizedSlice:
+		c := make(LinearizedSlice, len(v))
+		for key, elem := range v {
+			c[key] = cloneValue(elem)
+		}
+		return c
+	case LinearizedMap:
+		c := make(LinearizedMap, len(v))
+		for key, entry := range v {
+			c[key] = [2]any{cloneValue(entry[0]), cloneValue(entry[1])}
+		}
+		return c
+	case [2]any:
+		return [2]any{cloneValue(v[0]), cloneValue(v[1])}
+	case []byte:
+		if v == nil {
+			return v
+		}
+		return append([]byte{}, v...)
+	}
+	return value
+}
+
+// hashValue returns the root of the value's Merkle tree.
+func hashValue(value any) [32]byte {
+	return NewMerkleTree(value).Hash
+}
+
+// canonicalBytes returns an encoding in which scalars that are equal produce identical bytes.
+func canonicalBytes(value any) []byte {
+	var buf bytes.Buffer
+	writeCanonical(&buf, value)
+	return buf.Bytes()
+}
+
+// writeCanonical writes the canonical encoding of a scalar. Integers are written by value
+// and floats as float64 with a single NaN and zero.
+func writeCanonical(buf *bytes.Buffer, value any) {
+	writeLength := func(n int) {
+		var b [binary.MaxVarintLen64]byte
+		buf.Write(b[:binary.PutUvarint(b[:], uint64(n))])
+	}
+	writeUint := func(tag byte, n uint64) {
+		buf.WriteByte(tag)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], n)
+		buf.Write(b[:])
+	}
+
+	if i, ok := signedValue(value); ok {
+		if i < 0 {
+			writeUint('i', uint64(i))
+		} else {
+			writeUint('u', uint64(i))
+		}
+		return
+	}
+	if u, ok := unsignedValue(value); ok {
+		writeUint('u', u)
+		return
+	}
+	if f, ok := floatValue(value); ok {
+		switch {
+		case math.IsNaN(f):
+			f = math.NaN()
+		case f == 0:
+			f = 0
+		}
+		writeUint('f', math.Float64bits(f))
+		return
+	}
+
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte('n')
+	case bool:
+		if v {
+			buf.WriteByte('T')
+		} else {
+			buf.WriteByte('F')
+		}
+	case string:
+		buf.WriteByte('s')
+		writeLength(len(v))
+		buf.WriteString(v)
+	case []byte:
+		buf.WriteByte('b')
+		writeLength(len(v))
+		buf.Write(v)
+	default:
+		s := fmt.Sprintf("%T:%v", value, value)
+		buf.WriteByte('?')
+		writeLength(len(s))
+		buf.WriteString(s)
+	}
+}
@@ -0,0 +1,108 @@
+package linearize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	t.Run("should compare integers by value and NaN as equal", func(t *testing.T) {
+		// Arrange
+		a := LinearizedObject{1: int32(5), 2: math.NaN(), 3: uint64(7), 4: []byte("x")}
+		b := LinearizedObject{1: int64(5), 2: math.NaN(), 3: int32(7), 4: []byte("x")}
+
+		// Act
+		equal := a.Equal(b)
+
+		// Assert
+		assert.True(t, equal)
+		assert.Equal(t, a.Hash(), b.Hash())
+	})
+
+	t.Run("should detect differences", func(t *testing.T) {
+		// Arrange
+		linearized1, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+
+		msg2 := mocks.CreateSuperComplexMessage()
+		msg2.Nested.Map["key2"].Repeated[1] = "changed"
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+
+		// Act
+		equal := linearized1.Equal(linearized2)
+
+		// Assert
+		assert.False(t, equal)
+		assert.NotEqual(t, linearized1.Hash(), linearized2.Hash())
+		assert.False(t, LinearizedObject{1: int32(-1)}.Equal(LinearizedObject{1: uint64(math.MaxUint64)}))
+		assert.False(t, LinearizedObject{1: "1"}.Equal(LinearizedObject{1: []byte("1")}))
+	})
+
+	t.Run("should compare maps by key regardless of position", func(t *testing.T) {
+		// Arrange
+		a := LinearizedMap{0: {"a", int32(1)}, 1: {"b", int32(2)}}
+		b := LinearizedMap{0: {"b", int32(2)}, 1: {"a", int32(1)}}
+
+		// Act
+		equal := a.Equal(b)
+
+		// Assert
+		assert.True(t, equal)
+		assert.Equal(t, a.Hash(), b.Hash())
+	})
+
+	t.Run("should hash equal objects identically", func(t *testing.T) {
+		// Arrange
+		linearized1, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		linearized2, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+
+		// Act
+		hash1 := linearized1.Hash()
+		hash2 := linearized2.Hash()
+
+		// Assert
+		assert.Equal(t, hash1, hash2)
+		assert.True(t, linearized1.Equal(linearized2))
+	})
+
+	t.Run("should deep clone", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		// Act
+		clone := linearized.Clone()
+		clone[3].(LinearizedObject)[1] = "changed"
+		clone[5].(LinearizedMap)[0][1].(LinearizedObject)[2] = int32(0)
+
+		// Assert
+		assert.Equal(t, "test1", linearized[3].(LinearizedObject)[1])
+		assert.Equal(t, int32(42), linearized[5].(LinearizedMap)[0][1].(LinearizedObject)[2])
+		assert.False(t, clone.Equal(linearized))
+	})
+
+	t.Run("should not share nested values with the diff after merge", func(t *testing.T) {
+		// Arrange
+		linearized1, err := Linearize(&mocks.Complex{Field1: "value"})
+		require.NoError(t, err)
+		linearized2, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		_, diff, mask, err := Diff(linearized1, linearized2)
+		require.NoError(t, err)
+
+		// Act
+		require.NoError(t, Merge(mask, linearized1, diff))
+		diff[3].(LinearizedObject)[1] = "mutated"
+
+		// Assert
+		assert.Equal(t, "test1", linearized1[3].(LinearizedObject)[1])
+	})
+}
@@ -0,0 +1,144 @@
+package linearize
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ErrLossyFieldMask is matched by errors reporting that an UpdateMask could not be
+// represented exactly as a FieldMask.
+var ErrLossyFieldMask = errors.New("update mask is not exactly representable as a field mask")
+
+// LossyFieldMaskError lists the UpdateMask paths that were widened to their whole
+// repeated or map field because FieldMask cannot address elements.
+type LossyFieldMaskError struct {
+	Paths []string
+}
+
+func (e *LossyFieldMaskError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrLossyFieldMask, strings.Join(e.Paths, ", "))
+}
+
+func (e *LossyFieldMaskError) Is(target error) bool {
+	return target == ErrLossyFieldMask
+}
+
+// ToFieldMask converts an UpdateMask into a FieldMask using proto field names.
+// Changes to individual repeated elements or map entries are widened to the whole field;
+// when that happens the returned FieldMask is still usable and a *LossyFieldMaskError
+// lists the widened paths. As with Paths, map keys in those paths are looked up in objs,
+// usually the before and after objects returned by Diff.
+func ToFieldMask(mask *UpdateMask, md protoreflect.MessageDescriptor, objs ...LinearizedObject) (*fieldmaskpb.FieldMask, error) {
+	fm := &fieldmaskpb.FieldMask{}
+	var lossy []string
+	if err := appendFieldMaskPaths(fm, &lossy, "", mask, md, objectValues(objs)); err != nil {
+		return nil, err
+	}
+	fm.Normalize()
+
+	if len(lossy) > 0 {
+		return fm, &LossyFieldMaskError{Paths: lossy}
+	}
+	return fm, nil
+}
+
+// FromFieldMask converts a FieldMask into an UpdateMask marking every path as an UPDATE.
+func FromFieldMask(fm *fieldmaskpb.FieldMask, md protoreflect.MessageDescriptor) (*UpdateMask, error) {
+	for _, path := range fm.GetPaths() {
+		if strings.ContainsAny(path, "[]") {
+			return nil, fmt.Errorf("invalid field mask path %q", path)
+		}
+	}
+	return ParsePaths(fm.GetPaths(), md)
+}
+
+// PatchFromFieldMask prepares an update request for Merge following AIP-134: fields named by
+// the FieldMask are copied from the request, and fields that are unset in the request are removed.
+func PatchFromFieldMask(fm *fieldmaskpb.FieldMask, request proto.Message) (*UpdateMask, LinearizedObject, error) {
+	md := request.ProtoReflect().Descriptor()
+	mask, err := FromFieldMask(fm, md)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diff, err := Linearize(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preparePatch(mask, diff)
+	return mask, diff, nil
+}
+
+// preparePatch marks leaves missing from the diff as REMOVE and fills in the intermediate
+// objects Merge needs to reach nested leaves.
+func preparePatch(mask *UpdateMask, diff LinearizedObject) {
+	for pos, maskValue := range mask.Values {
+		if hasNestedMask(maskValue) {
+			nested, ok := diff[pos].(LinearizedObject)
+			if !ok {
+				nested = make(LinearizedObject)
+				diff[pos] = nested
+			}
+			preparePatch(maskValue.Masks, nested)
+			continue
+		}
+
+		if _, exists := diff[pos]; !exists {
+			maskValue.Op = UpdateMaskOperation_REMOVE
+		}
+	}
+}
+
+// appendFieldMaskPaths walks the mask and appends a FieldMask path for each leaf. values are the
+// values the mask applies to, used to name map entries in lossy paths.
+func appendFieldMaskPaths(fm *fieldmaskpb.FieldMask, lossy *[]string, prefix string, mask *UpdateMask, md protoreflect.MessageDescriptor, values []any) error {
+	if mask == nil {
+		return nil
+	}
+
+	for _, pos := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[pos]
+		fd := md.Fields().ByNumber(protoreflect.FieldNumber(pos))
+		if fd == nil {
+			return fmt.Errorf("field number %d not found in message %s", pos, md.FullName())
+		}
+
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		switch {
+		case !hasNestedMask(maskValue):
+			fm.Paths = append(fm.Paths, path)
+
+		case fd.IsList() || fd.IsMap():
+			// FieldMask cannot address list elements or map entries
+			fm.Paths = append(fm.Paths, path)
+			var elems []Path
+			appendMaskPaths(&elems, nil, &UpdateMask{Values: map[int32]*UpdateMaskValue{pos: maskValue}}, md, values)
+			for _, elem := range elems {
+				formatted := elem.Format(md)
+				if prefix != "" {
+					formatted = prefix + "." + formatted
+				}
+				*lossy = append(*lossy, formatted)
+			}
+
+		case fd.Message() != nil:
+			if err := appendFieldMaskPaths(fm, lossy, path, maskValue.Masks, fd.Message(), maskChildValues(values, pos)); err != nil {
+				return err
+			}
+
+		default:
+			fm.Paths = append(fm.Paths, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestFieldMask(t *testing.T) {
+	md := (&mocks.Complex{}).ProtoReflect().Descriptor()
+
+	t.Run("should convert mask to field mask", func(t *testing.T) {
+		// Arrange
+		mask, err := ParsePaths([]string{"Field1", "Nested.Field2"}, md)
+		require.NoError(t, err)
+
+		// Act
+		fm, err := ToFieldMask(mask, md)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Field1", "Nested.Field2"}, fm.Paths)
+	})
+
+	t.Run("should report lossy conversion of list elements", func(t *testing.T) {
+		// Arrange
+		mask, err := ParsePaths([]string{"Field1", "Repeated[1].Field2", "Nested.Repeated[0]"}, md)
+		require.NoError(t, err)
+
+		// Act
+		fm, err := ToFieldMask(mask, md)
+
+		// Assert
+		require.ErrorIs(t, err, ErrLossyFieldMask)
+		var lossy *LossyFieldMaskError
+		require.ErrorAs(t, err, &lossy)
+		assert.Equal(t, []string{"Nested.Repeated[0]", "Repeated[1].Field2"}, lossy.Paths)
+		assert.Equal(t, []string{"Field1", "Nested.Repeated", "Repeated"}, fm.Paths)
+	})
+
+	t.Run("should name map entries in lossy paths", func(t *testing.T) {
+		// Arrange
+		message := mocks.CreateComplexMessage()
+		previous, err := Linearize(message)
+		require.NoError(t, err)
+		message.Map["key2"].Field2++
+		latest, err := Linearize(message)
+		require.NoError(t, err)
+		before, after, mask, err := Diff(previous, latest)
+		require.NoError(t, err)
+
+		// Act
+		fm, err := ToFieldMask(mask, md, before, after)
+
+		// Assert
+		var lossy *LossyFieldMaskError
+		require.ErrorAs(t, err, &lossy)
+		assert.Equal(t, []string{`Map["key2"].Field2`}, lossy.Paths)
+		assert.Equal(t, []string{"Map"}, fm.Paths)
+	})
+
+	t.Run("should convert field mask to mask", func(t *testing.T) {
+		// Arrange
+		fm := &fieldmaskpb.FieldMask{Paths: []string{"Field1", "Nested.Field2"}}
+
+		// Act
+		mask, err := FromFieldMask(fm, md)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, fm.Paths, Paths(mask, md))
+	})
+
+	t.Run("should reject field mask paths through repeated fields", func(t *testing.T) {
+		// Arrange
+		fm := &fieldmaskpb.FieldMask{Paths: []string{"Repeated.Field1"}}
+
+		// Act
+		_, err := FromFieldMask(fm, md)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("should merge update request using field mask", func(t *testing.T) {
+		// Arrange
+		current, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		request := &mocks.Complex{
+			Field1: "ignored",
+			Field2: 7,
+			Nested: &mocks.Simple{Field1: "changed"},
+		}
+		fm := &fieldmaskpb.FieldMask{Paths: []string{"Field2", "Nested.Field1", "Nested.Field2", "Map"}}
+
+		// Act
+		mask, diff, err := PatchFromFieldMask(fm, request)
+		require.NoError(t, err)
+		err = Merge(mask, current, diff)
+
+		// Assert
+		require.NoError(t, err)
+		var merged mocks.Complex
+		require.NoError(t, Unlinearize(current, &merged))
+
+		expected := mocks.CreateComplexMessage()
+		expected.Field2 = 7
+		expected.Nested.Field1 = "changed"
+		expected.Nested.Field2 = 0
+		expected.Map = nil
+		assert.True(t, proto.Equal(expected, &merged))
+	})
+
+	t.Run("should create missing nested message when merging field mask", func(t *testing.T) {
+		// Arrange
+		current, err := Linearize(&mocks.Complex{Field1: "value"})
+		require.NoError(t, err)
+
+		request := &mocks.Complex{Nested: &mocks.Simple{Field1: "created"}}
+		fm := &fieldmaskpb.FieldMask{Paths: []string{"Nested.Field1"}}
+
+		// Act
+		mask, diff, err := PatchFromFieldMask(fm, request)
+		require.NoError(t, err)
+		err = Merge(mask, current, diff)
+
+		// Assert
+		require.NoError(t, err)
+		var merged mocks.Complex
+		require.NoError(t, Unlinearize(current, &merged))
+		assert.Equal(t, "value", merged.Field1)
+		assert.Equal(t, "created", merged.Nested.GetField1())
+	})
+}
@@ -0,0 +1,222 @@
+package linearize
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldFilter selects the fields that Linearize, Diff and Merge consider. It can be passed as an
+// option to all three, so the same filter keeps ignored fields out of objects, out of masks and
+// untouched by Merge. When several filters are given, a field must pass all of them.
+type FieldFilter struct {
+	md      protoreflect.MessageDescriptor
+	include bool
+	paths   *filterNode
+	keep    func(fd protoreflect.FieldDescriptor) bool
+}
+
+// filterNode is a field number trie built from filter paths. A terminal node selects its whole subtree.
+type filterNode struct {
+	terminal bool
+	children map[int32]*filterNode
+}
+
+// IncludeFields returns a filter that keeps only the named fields and everything below them.
+// Paths are dot-separated proto field names such as Nested.Field1, as in a FieldMask. Fields of
+// messages inside repeated and map fields are named without indices.
+func IncludeFields(md protoreflect.MessageDescriptor, paths ...string) (*FieldFilter, error) {
+	return newPathFilter(md, true, paths)
+}
+
+// ExcludeFields returns a filter that drops the named fields and everything below them.
+// Paths use the same format as IncludeFields.
+func ExcludeFields(md protoreflect.MessageDescriptor, paths ...string) (*FieldFilter, error) {
+	return newPathFilter(md, false, paths)
+}
+
+// FilterFields returns a filter that keeps the fields for which keep returns true, at any depth
+// of the message described by md.
+func FilterFields(md protoreflect.MessageDescriptor, keep func(fd protoreflect.FieldDescriptor) bool) *FieldFilter {
+	return &FieldFilter{md: md, keep: keep}
+}
+
+func newPathFilter(md protoreflect.MessageDescriptor, include bool, paths []string) (*FieldFilter, error) {
+	root := &filterNode{}
+	for _, path := range paths {
+		if strings.ContainsAny(path, "[]") {
+			return nil, fmt.Errorf("%w: %q: filters name fields, not elements", ErrInvalidPath, path)
+		}
+
+		node, current := root, md
+		for _, name := range strings.Split(path, ".") {
+			if current == nil {
+				return nil, fmt.Errorf("%w: %q: %s has no fields", ErrInvalidPath, path, name)
+			}
+			fd, err := lookupField(current, name)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %v", ErrInvalidPath, path, err)
+			}
+			current = fd.Message()
+			if fd.IsMap() {
+				current = fd.MapValue().Message()
+			}
+
+			if node.children == nil {
+				node.children = make(map[int32]*filterNode)
+			}
+			child, exists := node.children[int32(fd.Number())]
+			if !exists {
+				child = &filterNode{}
+				node.children[int32(fd.Number())] = child
+			}
+			node = child
+		}
+		node.terminal = true
+	}
+	return &FieldFilter{md: md, include: include, paths: root}, nil
+}
+
+func (f *FieldFilter) applyLinearize(o *linearizeOptions) {
+	o.filters = append(o.filters, f)
+}
+
+func (f *FieldFilter) applyDiff(o *diffOptions) {
+	o.filters = append(o.filters, f)
+}
+
+func (f *FieldFilter) applyMerge(o *mergeOptions) {
+	o.filters = append(o.filters, f)
+}
+
+// fieldScope tracks where a traversal is inside each active filter. An empty scope keeps every field.
+type fieldScope []filterState
+
+// filterState is the message being visited and the trie node that applies to it for one filter.
+type filterState struct {
+	filter *FieldFilter
+	md     protoreflect.MessageDescriptor
+	node   *filterNode
+}
+
+func newFieldScope(filters []*FieldFilter) fieldScope {
+	var scope fieldScope
+	for _, f := range filters {
+		if f != nil {
+			scope = append(scope, filterState{filter: f, md: f.md, node: f.paths})
+		}
+	}
+	return scope
+}
+
+// field reports whether a field of the current message is kept and returns the scope for its value.
+// Elements of repeated and map fields share the scope of the field.
+func (s fieldScope) field(number int32) (fieldScope, bool) {
+	if len(s) == 0 {
+		return nil, true
+	}
+
+	var child fieldScope
+	for _, state := range s {
+		next, ok := state.field(number)
+		if !ok {
+			return nil, false
+		}
+		// Filters that select the whole subtree no longer need to be tracked
+		if next.filter != nil {
+			child = append(child, next)
+		}
+	}
+	return child, true
+}
+
+func (s filterState) field(number int32) (filterState, bool) {
+	var fd protoreflect.FieldDescriptor
+	var md protoreflect.MessageDescriptor
+	if s.md != nil {
+		fd = s.md.Fields().ByNumber(protoreflect.FieldNumber(number))
+	}
+	if fd != nil {
+		md = fd.Message()
+		if fd.IsMap() {
+			md = fd.MapValue().Message()
+		}
+	}
+
+	if s.filter.keep != nil {
+		// Unknown fields cannot be judged by the predicate and are kept
+		if fd != nil && !s.filter.keep(fd) {
+			return filterState{}, false
+		}
+		return filterState{filter: s.filter, md: md}, true
+	}
+
+	node := s.node.children[number]
+	if s.filter.include {
+		if node == nil {
+			return filterState{}, false
+		}
+		if node.terminal {
+			return filterState{}, true
+		}
+	} else {
+		if node == nil {
+			return filterState{}, true
+		}
+		if node.terminal {
+			return filterState{}, false
+		}
+	}
+	return filterState{filter: s.filter, md: md, node: node}, true
+}
+
+// overlay returns a copy of the diff value in which fields outside the scope are taken from
+// the current value, so applying it never touches filtered fields.
+func (s fieldScope) overlay(current, diff any) any {
+	if len(s) == 0 {
+		return cloneValue(diff)
+	}
+
+	switch d := diff.(type) {
+	case LinearizedObject:
+		cur, _ := current.(LinearizedObject)
+		result := make(LinearizedObject, len(d))
+		for key, value := range d {
+			if child, ok := s.field(key); ok {
+				result[key] = child.overlay(cur[key], value)
+			}
+		}
+		for key, value := range cur {
+			if _, ok := s.field(key); !ok {
+				result[key] = cloneValue(value)
+			}
+		}
+		return result
+	case LinearizedSlice:
+		cur, _ := current.(LinearizedSlice)
+		result := make(LinearizedSlice, len(d))
+		for key, value := range d {
+			result[key] = s.overlay(cur[key], value)
+		}
+		return result
+	case LinearizedMap:
+		cur, _ := current.(LinearizedMap)
+		result := make(LinearizedMap, len(d))
+		for position, entry := range d {
+			var value any
+			if p := mapPosition(cur, entry[0]); p >= 0 {
+				value = cur[p][1]
+			}
+			result[position] = [2]any{cloneValue(entry[0]), s.overlay(value, entry[1])}
+		}
+		return result
+	case [2]any:
+		var value any
+		if cur, ok := current.([2]any); ok && mapKeysEqual(cur[0], d[0]) {
+			value = cur[1]
+		}
+		return [2]any{cloneValue(d[0]), s.overlay(value, d[1])}
+	}
+	return cloneValue(diff)
+}
@@ -0,0 +1,111 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestFilter(t *testing.T) {
+	md := (&mocks.SuperComplex{}).ProtoReflect().Descriptor()
+
+	changedMessage := func() *mocks.SuperComplex {
+		msg := mocks.CreateSuperComplexMessage()
+		msg.Field1 = "changed"
+		msg.Field2 = 501
+		msg.Nested.Field2 = 201
+		msg.Nested.Map["key1"].Field2 = 43
+		msg.Repeated[0].Field1 = "changed"
+		msg.Repeated[0].Field2 = 151
+		return msg
+	}
+
+	t.Run("should linearize included fields only", func(t *testing.T) {
+		// Arrange
+		filter, err := IncludeFields(md, "Field1", "Nested.Nested")
+		require.NoError(t, err)
+
+		// Act
+		linearized, err := Linearize(mocks.CreateSuperComplexMessage(), filter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1, 3}, sortedKeys(linearized))
+		nested := linearized[3].(LinearizedObject)
+		assert.Equal(t, []int32{3}, sortedKeys(nested))
+		assert.Equal(t, "simple_nested_field1", nested[3].(LinearizedObject)[1])
+	})
+
+	t.Run("should keep excluded fields out of masks", func(t *testing.T) {
+		// Arrange
+		filter, err := ExcludeFields(md, "Field2", "Nested.Field2", "Nested.Map.Field2", "Repeated.Field2")
+		require.NoError(t, err)
+		linearized1, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		linearized2, err := Linearize(changedMessage())
+		require.NoError(t, err)
+
+		// Act
+		_, _, mask, err := Diff(linearized1, linearized2, filter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Field1", "Repeated[0].Field1"}, Paths(mask, md))
+	})
+
+	t.Run("should filter fields by predicate", func(t *testing.T) {
+		// Arrange
+		filter := FilterFields(md, func(fd protoreflect.FieldDescriptor) bool {
+			return fd.Name() != "Field2"
+		})
+		linearized1, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		linearized2, err := Linearize(changedMessage())
+		require.NoError(t, err)
+
+		// Act
+		_, _, mask, err := Diff(linearized1, linearized2, filter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Field1", "Repeated[0].Field1"}, Paths(mask, md))
+	})
+
+	t.Run("should never touch filtered fields when merging", func(t *testing.T) {
+		// Arrange
+		filter := FilterFields(md, func(fd protoreflect.FieldDescriptor) bool {
+			return fd.Name() != "Field2"
+		})
+		current, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		latest, err := Linearize(changedMessage())
+		require.NoError(t, err)
+		_, after, mask, err := Diff(current, latest)
+		require.NoError(t, err)
+
+		// Act
+		err = Merge(mask, current, after, filter)
+
+		// Assert
+		require.NoError(t, err)
+		var msg mocks.SuperComplex
+		require.NoError(t, Unlinearize(current, &msg))
+		assert.Equal(t, "changed", msg.Field1)
+		assert.Equal(t, "changed", msg.Repeated[0].Field1)
+		assert.Equal(t, int32(500), msg.Field2)
+		assert.Equal(t, int32(200), msg.Nested.Field2)
+		assert.Equal(t, int32(42), msg.Nested.Map["key1"].Field2)
+		assert.Equal(t, int32(150), msg.Repeated[0].Field2)
+	})
+
+	t.Run("should reject element paths", func(t *testing.T) {
+		// Act
+		_, err := ExcludeFields(md, "Repeated[0].Field1")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+}
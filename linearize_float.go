@@ -0,0 +1,68 @@
+package linearize
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FloatTolerance configures how Diff compares float and double values. Two values are equal when
+// |a-b| <= Absolute or |a-b| <= Relative*max(|a|,|b|). NaN equals NaN, infinities only equal
+// themselves, and zeros of either sign are equal unless SignedZeros is set.
+type FloatTolerance struct {
+	Absolute    float64
+	Relative    float64
+	SignedZeros bool
+}
+
+// WithFloatTolerance sets the tolerance Diff uses for float and double values. Without fields it
+// applies to every field; otherwise it applies to the given fields only, which requires WithDescriptor.
+// Field tolerances take precedence over the float_tolerance field option, which takes precedence
+// over the global tolerance.
+func WithFloatTolerance(tolerance FloatTolerance, fields ...protoreflect.FieldDescriptor) DiffOption {
+	return diffOptionFunc(func(o *diffOptions) {
+		if len(fields) == 0 {
+			o.tolerance = tolerance
+			return
+		}
+		if o.fieldTolerances == nil {
+			o.fieldTolerances = make(map[protoreflect.FieldDescriptor]FloatTolerance)
+		}
+		for _, fd := range fields {
+			o.fieldTolerances[fd] = tolerance
+		}
+	})
+}
+
+// floatTolerance returns the tolerance that applies to the field being compared.
+func (d differ) floatTolerance() FloatTolerance {
+	if d.opts != nil && d.fd != nil {
+		if tolerance, ok := d.opts.fieldTolerances[d.fd]; ok {
+			return tolerance
+		}
+	}
+	if absolute := fieldOptions(d.fd).GetFloatTolerance(); absolute > 0 {
+		return FloatTolerance{Absolute: absolute}
+	}
+	if d.opts != nil {
+		return d.opts.tolerance
+	}
+	return FloatTolerance{}
+}
+
+// Equal reports whether two floats are equal within the tolerance.
+func (t FloatTolerance) Equal(a, b float64) bool {
+	switch {
+	case math.IsNaN(a) || math.IsNaN(b):
+		return math.IsNaN(a) && math.IsNaN(b)
+	case a == 0 && b == 0:
+		return !t.SignedZeros || math.Signbit(a) == math.Signbit(b)
+	case a == b:
+		return true
+	case math.IsInf(a, 0) || math.IsInf(b, 0):
+		return false
+	}
+
+	delta := math.Abs(a - b)
+	return delta <= t.Absolute || delta <= t.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
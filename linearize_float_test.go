@@ -0,0 +1,83 @@
+package linearize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloatTolerance(t *testing.T) {
+	t.Run("should treat NaN as equal to NaN", func(t *testing.T) {
+		// Act
+		_, _, mask, err := Diff(LinearizedObject{1: math.NaN(), 2: float32(math.NaN())}, LinearizedObject{1: math.NaN(), 2: float32(math.NaN())})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, mask)
+	})
+
+	t.Run("should compare signed zeros explicitly", func(t *testing.T) {
+		// Arrange
+		previous := LinearizedObject{1: 0.0}
+		latest := LinearizedObject{1: math.Copysign(0, -1)}
+
+		// Act
+		_, _, unsigned, err := Diff(previous, latest)
+		require.NoError(t, err)
+		_, _, signed, err := Diff(previous, latest, WithFloatTolerance(FloatTolerance{SignedZeros: true}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, unsigned)
+		assert.NotNil(t, signed)
+	})
+
+	t.Run("should ignore changes within a global tolerance", func(t *testing.T) {
+		// Arrange
+		previous := LinearizedObject{1: 100.0, 2: 0.5, 3: math.Inf(1)}
+		latest := LinearizedObject{1: 100.05, 2: 0.6, 3: math.Inf(1)}
+
+		// Act
+		_, _, mask, err := Diff(previous, latest, WithFloatTolerance(FloatTolerance{Relative: 0.001}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int32{2}, sortedMaskKeys(mask))
+	})
+
+	t.Run("should report a float replaced by a double within the tolerance", func(t *testing.T) {
+		// Arrange
+		previous := LinearizedObject{1: float32(1.5)}
+		latest := LinearizedObject{1: 1.5001}
+
+		// Act
+		_, _, mask, err := Diff(previous, latest, WithFloatTolerance(FloatTolerance{Absolute: 0.01}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1}, sortedMaskKeys(mask))
+	})
+
+	t.Run("should prefer field tolerances over field options", func(t *testing.T) {
+		// Arrange
+		md := (&mocks.Annotated{}).ProtoReflect().Descriptor()
+		temperature := md.Fields().ByName("Temperature")
+		previous, err := Linearize(&mocks.Annotated{Temperature: 20})
+		require.NoError(t, err)
+		latest, err := Linearize(&mocks.Annotated{Temperature: 20.5})
+		require.NoError(t, err)
+
+		// Act
+		_, _, withOption, err := Diff(previous, latest, WithDescriptor(md))
+		require.NoError(t, err)
+		_, _, withField, err := Diff(previous, latest, WithDescriptor(md), WithFloatTolerance(FloatTolerance{Absolute: 1}, temperature))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Temperature"}, Paths(withOption, md))
+		assert.Nil(t, withField)
+	})
+}
@@ -0,0 +1,120 @@
+package linearize
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Linearizer is implemented by messages with methods generated by protoc-gen-linearize.
+// Linearize uses it instead of protoreflect when no options are given.
+type Linearizer interface {
+	Linearize() (LinearizedObject, error)
+}
+
+// Unlinearizer is implemented by messages with methods generated by protoc-gen-linearize.
+// Unlinearize uses it instead of reflection.
+type Unlinearizer interface {
+	Unlinearize(obj LinearizedObject) error
+}
+
+// messageDiffer is implemented by messages with a Diff method generated by protoc-gen-linearize.
+// DiffMessages uses it instead of protoreflect.
+type messageDiffer[T proto.Message] interface {
+	Diff(latest T, opts ...DiffOption) (Patch, error)
+}
+
+// messageMerger is implemented by messages with a Merge method generated by protoc-gen-linearize.
+// ApplyPatch uses it instead of protoreflect.
+type messageMerger interface {
+	Merge(mask *UpdateMask, diff LinearizedObject, opts ...MergeOption) error
+}
+
+// MapKeyType is the set of Go types of Protobuf map keys.
+type MapKeyType interface {
+	string | int32 | int64 | uint32 | uint64 | bool
+}
+
+// SortedMapKeys returns the keys of a map in the order Linearize gives map entries by default.
+// It is used by generated code.
+func SortedMapKeys[K MapKeyType, V any](m map[K]V) []K {
+	return OrderedMapKeys(m, MapKeysLexical)
+}
+
+// OrderedMapKeys returns the keys of a map in the order WithMapKeyOrder gives map entries.
+// It is used by generated code.
+func OrderedMapKeys[K MapKeyType, V any](m map[K]V, order MapKeyOrder) []K {
+	type formattedKey struct {
+		key       K
+		formatted string
+	}
+	keys := make([]formattedKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, formattedKey{key: key, formatted: mapKeyString(key)})
+	}
+	less := func(a, b formattedKey) bool {
+		if order == MapKeysNatural {
+			return order.lessValue(a.key, b.key)
+		}
+		return a.formatted < b.formatted
+	}
+	slices.SortFunc(keys, func(a, b formattedKey) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+
+	sorted := make([]K, len(keys))
+	for i, key := range keys {
+		sorted[i] = key.key
+	}
+	return sorted
+}
+
+// mapKeyString formats a key like protoreflect.MapKey.String.
+func mapKeyString[K MapKeyType](key K) string {
+	switch k := any(key).(type) {
+	case string:
+		return k
+	case int32:
+		return strconv.FormatInt(int64(k), 10)
+	case int64:
+		return strconv.FormatInt(k, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(k), 10)
+	case uint64:
+		return strconv.FormatUint(k, 10)
+	case bool:
+		return strconv.FormatBool(k)
+	}
+	return ""
+}
+
+// As converts a linearized value to T. It is used by generated code; field names the field in errors.
+func As[T any](value any, field string) (T, error) {
+	v, ok := value.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("field %s: type mismatch: expected %T but got %T", field, zero, value)
+	}
+	return v, nil
+}
+
+// EnumAs converts a linearized enum value, which may also be given as an int32, to the enum type T.
+// It is used by generated code.
+func EnumAs[T ~int32](value any, field string) (T, error) {
+	switch v := value.(type) {
+	case protoreflect.EnumNumber:
+		return T(v), nil
+	case int32:
+		return T(v), nil
+	}
+	return 0, fmt.Errorf("field %s: type mismatch: expected enum but got %T", field, value)
+}
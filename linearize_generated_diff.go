@@ -0,0 +1,188 @@
+package linearize
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageDiff collects the comparison of two messages made by a Diff method generated by
+// protoc-gen-linearize. The generated code reads the fields without reflection and reports the ones
+// that may have changed; MessageDiff compares them with the options of DiffMessages, so both return
+// the same patch. Only changes are kept, so a nested comparison that found one is completed from the
+// linearized messages when it ends.
+type MessageDiff struct {
+	d       differ
+	visits  bool
+	changed bool
+	before  LinearizedObject
+	after   LinearizedObject
+	mask    *UpdateMask
+}
+
+// NewMessageDiff starts comparing two messages described by md. It is used by generated code.
+func NewMessageDiff(md protoreflect.MessageDescriptor, opts ...DiffOption) *MessageDiff {
+	options := newDiffOptions(append([]DiffOption{WithDescriptor(md)}, opts...))
+	d := options.differ()
+	return &MessageDiff{d: d, visits: !d.skipsEqual()}
+}
+
+// Patch returns the result of the comparison.
+func (m *MessageDiff) Patch() (Patch, error) {
+	var before, after LinearizedObject
+	var mask *UpdateMask
+	if m.changed {
+		before, after, mask = m.before, m.after, m.mask
+	}
+	before, after, mask, err := m.d.opts.finish(before, after, mask, nil)
+	if err != nil {
+		return Patch{}, err
+	}
+	return Patch{Mask: mask, Before: before, After: after}, nil
+}
+
+// VisitsEqual reports whether fields holding equal values must be reported too, because the options
+// limit the depth or distinguish signed zeros.
+func (m *MessageDiff) VisitsEqual() bool {
+	return m.visits
+}
+
+// Changed reports whether the comparison found a change.
+func (m *MessageDiff) Changed() bool {
+	return m.changed
+}
+
+// Field compares the linearized values of a field. prevSet and latestSet tell whether the field is
+// populated in each message; the value of a field that is not populated is ignored.
+func (m *MessageDiff) Field(key int32, prevSet, latestSet bool, prev, latest any) {
+	child, ok := m.d.field(key)
+	if !ok {
+		return
+	}
+	switch {
+	case prevSet && latestSet:
+		if changed, before, after, mask := child.compare(prev, latest, nil, nil); changed {
+			m.set(key, before, after, &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: mask})
+		}
+	case prevSet:
+		m.set(key, prev, nil, &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE})
+	case latestSet:
+		m.set(key, nil, latest, &UpdateMaskValue{Op: UpdateMaskOperation_ADD})
+	}
+}
+
+// Message starts comparing a message field populated in both messages, or returns nil when the
+// field is filtered out.
+func (m *MessageDiff) Message(key int32) *MessageDiff {
+	child, ok := m.d.field(key)
+	if !ok {
+		return nil
+	}
+	return &MessageDiff{d: child, visits: m.visits}
+}
+
+// EndMessage records a comparison started by Message that found a change. prev and latest are the
+// linearized messages, which provide the unchanged fields of the before and after objects.
+func (m *MessageDiff) EndMessage(key int32, nested *MessageDiff, prev, latest LinearizedObject) {
+	before, after := nested.complete(prev, latest)
+	m.set(key, before, after, &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: nested.mask})
+}
+
+// List starts comparing a repeated message field populated in both messages element by element,
+// or returns nil when the field is filtered out or must be compared as a whole with Field.
+func (m *MessageDiff) List(key int32, prevLen, latestLen int) *ListDiff {
+	child, ok := m.d.field(key)
+	if !ok || child.opts.listStrategy == ListReplace {
+		return nil
+	}
+	return &ListDiff{d: child, visits: m.visits, prevLen: prevLen, latestLen: latestLen}
+}
+
+// EndList records a comparison started by List that found a change. prev is the linearized list,
+// which provides the unchanged elements of the before list.
+func (m *MessageDiff) EndList(key int32, list *ListDiff, prev LinearizedSlice) {
+	for index, elem := range prev {
+		if _, changed := list.before[index]; !changed {
+			list.before[index] = elem
+		}
+	}
+	m.set(key, list.before, list.after, &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: list.mask})
+}
+
+// set records a changed field.
+func (m *MessageDiff) set(key int32, before, after any, value *UpdateMaskValue) {
+	if !m.changed {
+		m.changed = true
+		m.before = make(LinearizedObject)
+		m.after = make(LinearizedObject)
+		m.mask = &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+	}
+	m.before[key] = before
+	m.after[key] = after
+	m.mask.Values[key] = value
+}
+
+// complete overlays the changed fields on the linearized messages, which hold the unchanged ones.
+func (m *MessageDiff) complete(prev, latest LinearizedObject) (before, after LinearizedObject) {
+	for key, value := range m.before {
+		prev[key] = value
+	}
+	for key, value := range m.after {
+		latest[key] = value
+	}
+	return prev, latest
+}
+
+// ListDiff collects the comparison of two lists of messages by position, like compareLists does.
+type ListDiff struct {
+	d         differ
+	visits    bool
+	prevLen   int
+	latestLen int
+	changed   bool
+	before    LinearizedSlice
+	after     LinearizedSlice
+	mask      *UpdateMask
+}
+
+// Len returns the number of positions to compare.
+func (l *ListDiff) Len() int {
+	return max(l.prevLen, l.latestLen)
+}
+
+// Changed reports whether the comparison found a change.
+func (l *ListDiff) Changed() bool {
+	return l.changed
+}
+
+// Element starts comparing two elements at the same position.
+func (l *ListDiff) Element() *MessageDiff {
+	return &MessageDiff{d: l.d, visits: l.visits}
+}
+
+// EndElement records a comparison started by Element that found a change, completing it like EndMessage.
+func (l *ListDiff) EndElement(index int, elem *MessageDiff, prev, latest LinearizedObject) {
+	before, after := elem.complete(prev, latest)
+	l.set(index, before, after, elem.mask)
+}
+
+// Change records an element present in only one of the lists.
+func (l *ListDiff) Change(index int, prev, latest any) {
+	l.set(index, prev, latest, nil)
+}
+
+// set records a changed element. When the list shrank, the last element of the previous list is removed.
+func (l *ListDiff) set(index int, before, after any, mask *UpdateMask) {
+	if !l.changed {
+		l.changed = true
+		l.before = make(LinearizedSlice, l.Len())
+		l.after = make(LinearizedSlice, l.Len())
+		l.mask = &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+	}
+	key := int32(index)
+	l.before[key] = before
+	if l.latestLen < l.prevLen && index >= l.prevLen-1 {
+		l.mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE}
+		return
+	}
+	l.after[key] = after
+	l.mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: mask}
+}
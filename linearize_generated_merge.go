@@ -0,0 +1,70 @@
+package linearize
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageMerge carries the options of a Merge method generated by protoc-gen-linearize, which sets
+// the fields named by a mask without reflection the way MergeInto does.
+type MessageMerge struct {
+	options *mergeOptions
+}
+
+// defaultMessageMerge is the merge without options, which is never modified.
+var defaultMessageMerge = MessageMerge{options: &mergeOptions{}}
+
+// NewMessageMerge prepares a merge into a message described by md. It returns nil when the options
+// filter fields or check the result, or when a field of the message is marked diff_ignore; the patch
+// must then be merged by MergeInto. It is used by generated code.
+func NewMessageMerge(md protoreflect.MessageDescriptor, opts ...MergeOption) *MessageMerge {
+	if ignoresFields(md) {
+		return nil
+	}
+	if len(opts) == 0 {
+		return &defaultMessageMerge
+	}
+	options := newMergeOptions(opts)
+	if len(options.filters) > 0 || len(options.checks) > 0 {
+		return nil
+	}
+	return &MessageMerge{options: options}
+}
+
+// Check rejects a patch that MergeInto would reject before merging it.
+func (m *MessageMerge) Check(mask *UpdateMask, diff LinearizedObject) error {
+	if err := findRedacted(mask, diff); err != nil {
+		return err
+	}
+	return m.options.limits.check(context.Background(), diff)
+}
+
+// MapKeyOrder returns the order that map positions refer to.
+func (m *MessageMerge) MapKeyOrder() MapKeyOrder {
+	return m.options.mapKeyOrder
+}
+
+// Message merges a mask into a message without generated methods through protoreflect.
+func (m *MessageMerge) Message(msg proto.Message, mask *UpdateMask, diff LinearizedObject) error {
+	return m.options.mergeIntoMessage(context.Background(), msg.ProtoReflect(), mask, diff, nil)
+}
+
+// Linearize linearizes a message with the map key order of the merge.
+func (m *MessageMerge) Linearize(msg proto.Message) (LinearizedObject, error) {
+	if m.options.mapKeyOrder == MapKeysLexical {
+		return Linearize(msg)
+	}
+	return Linearize(msg, WithMapKeyOrder(m.options.mapKeyOrder))
+}
+
+// KeyedList merges a mask with a ListKey into the linearized elements of a list by key.
+func (m *MessageMerge) KeyedList(mask *UpdateMask, current, diff LinearizedSlice) error {
+	return mergeKeyedSlices(mask, current, diff, nil)
+}
+
+// SortedPositions returns the positions of a mask in ascending order. It is used by generated code.
+func SortedPositions(mask *UpdateMask) []int32 {
+	return sortedMaskKeys(mask)
+}
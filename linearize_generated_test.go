@@ -0,0 +1,51 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestGeneratedHelpers(t *testing.T) {
+	t.Run("should sort map keys like Linearize", func(t *testing.T) {
+		// Arrange
+		m := map[int32]string{2: "two", 10: "ten", -1: "minus one"}
+
+		// Act
+		keys := SortedMapKeys(m)
+
+		// Assert
+		assert.Equal(t, []int32{-1, 10, 2}, keys)
+	})
+
+	t.Run("should convert values of the expected type", func(t *testing.T) {
+		// Act
+		value, err := As[int32](int32(7), "Field2")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(7), value)
+	})
+
+	t.Run("should name the field in type mismatches", func(t *testing.T) {
+		// Act
+		_, err := As[int32]("7", "Field2")
+
+		// Assert
+		assert.ErrorContains(t, err, "field Field2: type mismatch")
+	})
+
+	t.Run("should accept enums as numbers", func(t *testing.T) {
+		// Act
+		fromEnum, enumErr := EnumAs[int32](protoreflect.EnumNumber(2), "Color")
+		fromInt, intErr := EnumAs[int32](int32(3), "Color")
+
+		// Assert
+		require.NoError(t, enumErr)
+		require.NoError(t, intErr)
+		assert.Equal(t, int32(2), fromEnum)
+		assert.Equal(t, int32(3), fromInt)
+	})
+}
@@ -0,0 +1,201 @@
+package linearize
+
+import (
+	"fmt"
+	"sort"
+)
+
+// keyedEntry is a position in the union of two keyed lists, holding the index of the element in
+// the previous list, the latest list or both. Missing indices are -1.
+type keyedEntry struct {
+	prev   int
+	latest int
+}
+
+// elementKeys returns the keys of the elements of a slice in index order, or false when an element
+// is not an object or a key repeats.
+func elementKeys(slice LinearizedSlice, key int32) ([]string, bool) {
+	keys := make([]string, len(slice))
+	seen := make(map[string]bool, len(slice))
+	for i := range keys {
+		obj, ok := slice[int32(i)].(LinearizedObject)
+		if !ok {
+			return nil, false
+		}
+		k := elementKey(obj, key)
+		if seen[k] {
+			return nil, false
+		}
+		seen[k] = true
+		keys[i] = k
+	}
+	return keys, true
+}
+
+// elementKey returns the key of an element as a string that is equal only for equal keys of the same type.
+func elementKey(obj LinearizedObject, key int32) string {
+	return fmt.Sprintf("%T:%v", obj[key], obj[key])
+}
+
+// unionKeyed merges two keyed lists into one sequence. Elements whose key is in both lists and
+// whose relative order is kept appear once; the others appear as removed from the previous list,
+// followed by the elements inserted into the latest list in their place.
+func unionKeyed(prevKeys, latestKeys []string) []keyedEntry {
+	latestIndex := make(map[string]int, len(latestKeys))
+	for j, k := range latestKeys {
+		latestIndex[k] = j
+	}
+	var matched []keyedEntry
+	for i, k := range prevKeys {
+		if j, ok := latestIndex[k]; ok {
+			matched = append(matched, keyedEntry{prev: i, latest: j})
+		}
+	}
+
+	union := make([]keyedEntry, 0, len(prevKeys)+len(latestKeys))
+	i, j := 0, 0
+	for _, anchor := range append(longestIncreasing(matched), keyedEntry{prev: len(prevKeys), latest: len(latestKeys)}) {
+		for ; i < anchor.prev; i++ {
+			union = append(union, keyedEntry{prev: i, latest: -1})
+		}
+		for ; j < anchor.latest; j++ {
+			union = append(union, keyedEntry{prev: -1, latest: j})
+		}
+		if i < len(prevKeys) {
+			union = append(union, anchor)
+			i, j = i+1, j+1
+		}
+	}
+	return union
+}
+
+// longestIncreasing returns the longest subsequence of matched elements whose latest indices
+// increase, which are the elements that kept their relative order.
+func longestIncreasing(matched []keyedEntry) []keyedEntry {
+	var tails []int                    // index into matched of the smallest tail of each subsequence length
+	links := make([]int, len(matched)) // index into matched of the previous element, or -1
+	for m, entry := range matched {
+		length := sort.Search(len(tails), func(n int) bool {
+			return matched[tails[n]].latest >= entry.latest
+		})
+		links[m] = -1
+		if length > 0 {
+			links[m] = tails[length-1]
+		}
+		if length == len(tails) {
+			tails = append(tails, m)
+		} else {
+			tails[length] = m
+		}
+	}
+
+	result := make([]keyedEntry, len(tails))
+	for n, m := len(tails)-1, -1; n >= 0; n-- {
+		if m < 0 {
+			m = tails[n]
+		} else {
+			m = links[m]
+		}
+		result[n] = matched[m]
+	}
+	return result
+}
+
+// compareKeyed compares the elements of a repeated field with a list_key by key instead of by
+// index. The mask is indexed by position in the union of both lists built by unionKeyed, so an
+// element inserted in the middle is a single ADD and the elements after it are compared with their
+// previous versions: without removals the position is the index in the latest list, without
+// insertions the index in the previous list. Removed elements are REMOVEs, and elements that
+// changed are UPDATEs with the mask of their fields. The mask's ListKey names the key field so
+// Merge matches the elements by key too. before and after are indexed like the lists they come
+// from, and after holds every element of the latest list, which mergeKeyedSlices needs to restore
+// their order. ok is false when the elements cannot be matched by key.
+func (d differ) compareKeyed(key int32, prev, latest LinearizedSlice, prevNode, latestNode *MerkleNode) (ok, changed bool, before, after LinearizedSlice, mask *UpdateMask) {
+	prevKeys, prevOk := elementKeys(prev, key)
+	latestKeys, latestOk := elementKeys(latest, key)
+	if !prevOk || !latestOk {
+		return false, false, nil, nil, nil
+	}
+
+	union := unionKeyed(prevKeys, latestKeys)
+	results := make([]comparison, len(union))
+	d.parallel(len(union), parallelChunk, func(n int) {
+		if entry := union[n]; entry.prev >= 0 && entry.latest >= 0 {
+			p, l := int32(entry.prev), int32(entry.latest)
+			result := &results[n]
+			result.changed, result.before, result.after, result.mask = d.compare(prev[p], latest[l], prevNode.Child(p), latestNode.Child(l))
+		}
+	})
+
+	before = make(LinearizedSlice, len(prev))
+	after = make(LinearizedSlice, len(latest))
+	mask = &UpdateMask{Values: make(map[int32]*UpdateMaskValue), ListKey: key}
+	for n, entry := range union {
+		pos, p, l := int32(n), int32(entry.prev), int32(entry.latest)
+		switch {
+		case entry.latest < 0:
+			before[p] = prev[p]
+			mask.Values[pos] = &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE}
+		case entry.prev < 0:
+			after[l] = latest[l]
+			mask.Values[pos] = &UpdateMaskValue{Op: UpdateMaskOperation_ADD}
+		case results[n].changed:
+			before[p], after[l] = results[n].before, results[n].after
+			mask.Values[pos] = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: results[n].mask}
+		default:
+			before[p], after[l] = prev[p], latest[l]
+			continue
+		}
+		changed = true
+	}
+	return true, changed, before, after, mask
+}
+
+// mergeKeyedSlices applies a diff of a repeated field with a list_key produced by compareKeyed,
+// matching elements by the mask's ListKey. current takes the order of the latest list held by diff.
+// Elements named in the mask are taken from the diff and the others are kept from current, matched
+// by key; elements of current whose key is not in the latest list are dropped.
+func mergeKeyedSlices(mask *UpdateMask, current, diff LinearizedSlice, scope fieldScope) error {
+	key := mask.GetListKey()
+	byKey := make(map[string]any, len(current))
+	for _, elem := range current {
+		obj, ok := elem.(LinearizedObject)
+		if !ok {
+			return fmt.Errorf("expected object in keyed list but got %T", elem)
+		}
+		byKey[elementKey(obj, key)] = obj
+	}
+
+	// Positions in the mask that are not removals follow the elements of the latest list in order
+	merged := make([]any, 0, len(diff))
+	pos := int32(0)
+	for index := int32(0); index < int32(len(diff)); index++ {
+		for mask.Values[pos] != nil && mask.Values[pos].Op == UpdateMaskOperation_REMOVE {
+			pos++
+		}
+		maskValue := mask.Values[pos]
+		pos++
+
+		obj, ok := diff[index].(LinearizedObject)
+		if !ok {
+			return fmt.Errorf("expected object at index %d of keyed list diff but got %T", index, diff[index])
+		}
+		existing, exists := byKey[elementKey(obj, key)]
+		switch {
+		case maskValue != nil:
+			merged = append(merged, scope.overlay(existing, obj))
+		case exists:
+			merged = append(merged, existing)
+		default:
+			merged = append(merged, scope.overlay(nil, obj))
+		}
+	}
+
+	for index := range current {
+		delete(current, index)
+	}
+	for i, elem := range merged {
+		current[int32(i)] = elem
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package linearize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTooLarge is returned when an object has more nodes, list elements or map entries than the configured limits allow.
+var ErrTooLarge = errors.New("object too large")
+
+// Limits bounds the objects handled by Linearize, Diff and Merge so untrusted input can be processed
+// safely. Zero fields are unlimited. Exceeding MaxDepth fails with ErrDepthExceeded and the other
+// limits with ErrTooLarge.
+type Limits struct {
+	MaxDepth       int // deepest message nesting, the top-level message has depth 1
+	MaxNodes       int // total number of fields, list elements and map entries
+	MaxSliceLength int // longest repeated field
+	MaxMapSize     int // largest map field
+}
+
+// LimitsOption enforces Limits. It implements LinearizeOption, DiffOption and MergeOption.
+type LimitsOption Limits
+
+// WithLimits returns an option enforcing limits. Diff checks both objects and Merge checks the diff
+// before changing anything.
+func WithLimits(limits Limits) LimitsOption {
+	return LimitsOption(limits)
+}
+
+func (o LimitsOption) applyLinearize(opts *linearizeOptions) {
+	if o.MaxDepth > 0 {
+		opts.maxDepth = o.MaxDepth
+	}
+	opts.limiter.limits = Limits(o)
+}
+
+func (o LimitsOption) applyDiff(opts *diffOptions) {
+	if o.MaxDepth > 0 {
+		opts.maxDepth = o.MaxDepth
+	}
+	opts.limits = Limits(o)
+}
+
+func (o LimitsOption) applyMerge(opts *mergeOptions) {
+	opts.limits = Limits(o)
+}
+
+// check walks an object, failing when it exceeds the limits or the context is done.
+func (l Limits) check(ctx context.Context, obj LinearizedObject) error {
+	if l == (Limits{}) {
+		return nil
+	}
+	checker := limiter{limits: l, ctx: ctx}
+	return checker.walk(obj, 1)
+}
+
+// limiter enforces Limits while an object is visited, checking the context at every message.
+type limiter struct {
+	limits Limits
+	ctx    context.Context
+	nodes  int
+}
+
+// enter reports the context's error once it is done.
+func (l *limiter) enter() error {
+	if l.ctx == nil {
+		return nil
+	}
+	return l.ctx.Err()
+}
+
+// add counts visited nodes.
+func (l *limiter) add(nodes int) error {
+	if l.limits.MaxNodes == 0 {
+		return nil
+	}
+	l.nodes += nodes
+	if l.nodes > l.limits.MaxNodes {
+		return fmt.Errorf("%w: more than %d nodes", ErrTooLarge, l.limits.MaxNodes)
+	}
+	return nil
+}
+
+// list checks the length of a repeated field and counts its elements.
+func (l *limiter) list(length int) error {
+	if l.limits.MaxSliceLength > 0 && length > l.limits.MaxSliceLength {
+		return fmt.Errorf("%w: %d list elements exceed the limit of %d", ErrTooLarge, length, l.limits.MaxSliceLength)
+	}
+	return l.add(length)
+}
+
+// mapping checks the size of a map field and counts its entries.
+func (l *limiter) mapping(size int) error {
+	if l.limits.MaxMapSize > 0 && size > l.limits.MaxMapSize {
+		return fmt.Errorf("%w: %d map entries exceed the limit of %d", ErrTooLarge, size, l.limits.MaxMapSize)
+	}
+	return l.add(size)
+}
+
+// walk checks a linearized value held by an object at the given depth.
+func (l *limiter) walk(value any, depth int) error {
+	switch v := value.(type) {
+	case LinearizedObject:
+		if l.limits.MaxDepth > 0 && depth > l.limits.MaxDepth {
+			return fmt.Errorf("%w: %d", ErrDepthExceeded, l.limits.MaxDepth)
+		}
+		if err := l.enter(); err != nil {
+			return err
+		}
+		if err := l.add(len(v)); err != nil {
+			return err
+		}
+		for _, field := range v {
+			if err := l.walk(field, depth+1); err != nil {
+				return err
+			}
+		}
+	case LinearizedSlice:
+		if err := l.list(len(v)); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if err := l.walk(elem, depth); err != nil {
+				return err
+			}
+		}
+	case LinearizedMap:
+		if err := l.mapping(len(v)); err != nil {
+			return err
+		}
+		for _, entry := range v {
+			if err := l.walk(entry[1], depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package linearize
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// countNodes returns the number of nodes Limits counts in an object.
+func countNodes(t *testing.T, obj LinearizedObject) int {
+	checker := limiter{limits: Limits{MaxNodes: math.MaxInt}}
+	require.NoError(t, checker.walk(obj, 1))
+	return checker.nodes
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("should linearize messages within the limits", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		expected, err := Linearize(msg)
+		require.NoError(t, err)
+		limits := Limits{MaxDepth: 3, MaxNodes: countNodes(t, expected), MaxSliceLength: 2, MaxMapSize: 2}
+
+		// Act
+		actual, err := LinearizeContext(context.Background(), msg, WithLimits(limits))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should reject messages exceeding a limit", func(t *testing.T) {
+		msg := mocks.CreateSuperComplexMessage()
+		obj, err := Linearize(msg)
+		require.NoError(t, err)
+
+		limits := map[string]Limits{
+			"nodes":        {MaxNodes: countNodes(t, obj) - 1},
+			"slice length": {MaxSliceLength: 1},
+			"map size":     {MaxMapSize: 1},
+		}
+		for name, limit := range limits {
+			t.Run("should reject "+name, func(t *testing.T) {
+				// Act
+				_, linearizeErr := Linearize(msg, WithLimits(limit))
+				_, _, _, diffErr := Diff(obj, obj, WithLimits(limit))
+				mergeErr := Merge(&UpdateMask{}, make(LinearizedObject), obj, WithLimits(limit))
+
+				// Assert
+				assert.ErrorIs(t, linearizeErr, ErrTooLarge)
+				assert.ErrorIs(t, diffErr, ErrTooLarge)
+				assert.ErrorIs(t, mergeErr, ErrTooLarge)
+			})
+		}
+	})
+
+	t.Run("should reject messages nested too deeply", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		obj, err := Linearize(msg)
+		require.NoError(t, err)
+		limits := WithLimits(Limits{MaxDepth: 2})
+
+		// Act
+		_, linearizeErr := Linearize(msg, limits)
+		_, _, _, diffErr := Diff(obj, obj, limits)
+		mergeErr := Merge(&UpdateMask{}, make(LinearizedObject), obj, limits)
+
+		// Assert
+		assert.ErrorIs(t, linearizeErr, ErrDepthExceeded)
+		assert.ErrorIs(t, diffErr, ErrDepthExceeded)
+		assert.ErrorIs(t, mergeErr, ErrDepthExceeded)
+	})
+
+	t.Run("should leave the current object unchanged when the diff is too large", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+		latest.Nested.Map["key3"] = mocks.CreateSimpleMessage()
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+		current, err := Linearize(previous)
+		require.NoError(t, err)
+		expected := current.Clone()
+
+		// Act
+		err = Merge(patch.Mask, current, patch.After, WithLimits(Limits{MaxMapSize: 2}))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrTooLarge)
+		assert.Equal(t, expected, current)
+	})
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		obj, err := Linearize(msg)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		_, linearizeErr := LinearizeContext(ctx, msg)
+		_, _, _, diffErr := DiffContext(ctx, obj, obj)
+		mergeErr := MergeContext(ctx, &UpdateMask{}, make(LinearizedObject), obj)
+
+		// Assert
+		assert.ErrorIs(t, linearizeErr, context.Canceled)
+		assert.ErrorIs(t, diffErr, context.Canceled)
+		assert.ErrorIs(t, mergeErr, context.Canceled)
+	})
+
+	t.Run("should stop merging when the context is cancelled midway", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+		latest.Field1 = "changed"
+		latest.Field2 = 7
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+		current, err := Linearize(previous)
+		require.NoError(t, err)
+		expected := current.Clone()
+		msg := mocks.CreateSuperComplexMessage()
+
+		// Act
+		mergeErr := MergeContext(&countdownContext{Context: context.Background(), calls: 2}, patch.Mask, current, patch.After)
+		mergeIntoErr := MergeIntoContext(&countdownContext{Context: context.Background(), calls: 2}, msg, patch.Mask, patch.After)
+
+		// Assert
+		assert.ErrorIs(t, mergeErr, context.Canceled)
+		assert.Equal(t, expected, current)
+		assert.ErrorIs(t, mergeIntoErr, context.Canceled)
+		assert.True(t, proto.Equal(previous, msg))
+	})
+}
+
+// countdownContext is a context that is cancelled once Err has been called a number of times.
+type countdownContext struct {
+	context.Context
+	calls int
+}
+
+func (c *countdownContext) Done() <-chan struct{} {
+	return make(chan struct{})
+}
+
+func (c *countdownContext) Err() error {
+	if c.calls == 0 {
+		return context.Canceled
+	}
+	c.calls--
+	return nil
+}
@@ -1,34 +1,159 @@
 package linearize
 
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MergeOption configures Merge.
+type MergeOption interface {
+	applyMerge(*mergeOptions)
+}
+
+type mergeOptions struct {
+	filters     []*FieldFilter
+	checks      []func(merged LinearizedObject) error
+	limits      Limits
+	mapKeyOrder MapKeyOrder
+}
+
+func newMergeOptions(opts []MergeOption) *mergeOptions {
+	var options mergeOptions
+	for _, opt := range opts {
+		opt.applyMerge(&options)
+	}
+	return &options
+}
+
+// mergeOptionFunc adapts a function to a MergeOption.
+type mergeOptionFunc func(*mergeOptions)
+
+func (f mergeOptionFunc) applyMerge(o *mergeOptions) {
+	f(o)
+}
+
+// WithValidation makes Merge validate the merged object against the message descriptor.
+// When validation fails Merge returns a *ValidationError and leaves the current object unchanged.
+func WithValidation(md protoreflect.MessageDescriptor) MergeOption {
+	return WithCheck(func(merged LinearizedObject) error {
+		return Validate(merged, md)
+	})
+}
+
+// WithCheck runs check on the merged object before Merge commits it. When check returns an error
+// Merge returns it and leaves the current object unchanged. Checks run in the order they are given.
+func WithCheck(check func(merged LinearizedObject) error) MergeOption {
+	return mergeOptionFunc(func(o *mergeOptions) {
+		o.checks = append(o.checks, check)
+	})
+}
+
 // Merge applies the UpdateMask operations (ADD, UPDATE, REMOVE) to the current LinearizedObject
-// directly modifying it using the diff and the UpdateMask.
-func Merge(mask *UpdateMask, current LinearizedObject, diff LinearizedObject) error {
+// directly modifying it using the diff and the UpdateMask. Diffs carrying Redacted placeholders in
+// the values the mask applies are rejected with ErrRedacted.
+func Merge(mask *UpdateMask, current LinearizedObject, diff LinearizedObject, opts ...MergeOption) error {
+	return MergeContext(context.Background(), mask, current, diff, opts...)
+}
+
+// MergeContext applies a patch like Merge, checking the context at every field it merges. Once the
+// context is done it returns the context's error and leaves the current object unchanged.
+func MergeContext(ctx context.Context, mask *UpdateMask, current LinearizedObject, diff LinearizedObject, opts ...MergeOption) error {
+	if err := findRedacted(mask, diff); err != nil {
+		return err
+	}
+
+	options := newMergeOptions(opts)
+	if err := options.limits.check(ctx, diff); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	scope := newFieldScope(options.filters)
+	if len(options.checks) == 0 && ctx.Done() == nil {
+		return merge(ctx, mask, current, diff, scope)
+	}
+
+	// Merge into a copy so a rejected patch or a cancellation leaves no partial changes behind
+	merged := current.Clone()
+	if merged == nil {
+		merged = make(LinearizedObject)
+	}
+	if err := merge(ctx, mask, merged, diff, scope); err != nil {
+		return err
+	}
+	for _, check := range options.checks {
+		if err := check(merged); err != nil {
+			return err
+		}
+	}
+
+	for key := range current {
+		delete(current, key)
+	}
+	for key, value := range merged {
+		current[key] = value
+	}
+	return nil
+}
+
+// merge applies the mask to the fields of current that are in scope, without validation.
+func merge(ctx context.Context, mask *UpdateMask, current LinearizedObject, diff LinearizedObject, scope fieldScope) error {
 	// Apply operations based on the mask
 	for pos, maskValue := range mask.Values {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		nested, ok := scope.field(pos)
+		if !ok {
+			// Filtered fields are never touched
+			continue
+		}
+
 		switch maskValue.Op {
 		case UpdateMaskOperation_ADD, UpdateMaskOperation_UPDATE:
 
 			// If there's a nested mask, merge recursively for nested structures
 			if maskValue.Masks != nil {
+				// Create missing nested objects so nested leaves can be reached
+				if diffObj, ok := diff[pos].(LinearizedObject); ok && current[pos] == nil {
+					current[pos] = make(LinearizedObject, len(diffObj))
+				}
+
 				if nestedVal, exists := current[pos]; exists {
 					// Handle nested structures: LinearizedObject, LinearizedSlice, LinearizedMap
 					switch nestedVal := nestedVal.(type) {
 					case LinearizedObject:
 						// Recursively merge LinearizedObjects
-						err := Merge(maskValue.Masks, nestedVal, diff[pos].(LinearizedObject))
-						if err != nil {
+						diffObj, ok := diff[pos].(LinearizedObject)
+						if !ok {
+							return fmt.Errorf("field %d: expected object in diff but got %T", pos, diff[pos])
+						}
+						if err := merge(ctx, maskValue.Masks, nestedVal, diffObj, nested); err != nil {
 							return err
 						}
 					case LinearizedSlice:
 						// Handle merging of LinearizedSlice (slices)
-						err := mergeSlices(maskValue.Masks, nestedVal, diff[pos].(LinearizedSlice))
-						if err != nil {
+						diffSlice, ok := diff[pos].(LinearizedSlice)
+						if !ok {
+							return fmt.Errorf("field %d: expected slice in diff but got %T", pos, diff[pos])
+						}
+						if maskValue.Masks.GetListKey() != 0 {
+							if err := mergeKeyedSlices(maskValue.Masks, nestedVal, diffSlice, nested); err != nil {
+								return fmt.Errorf("field %d: %w", pos, err)
+							}
+						} else if err := mergeSlices(maskValue.Masks, nestedVal, diffSlice, nested); err != nil {
 							return err
 						}
 					case LinearizedMap:
 						// Handle merging of LinearizedMap
-						err := mergeMaps(maskValue.Masks, nestedVal, diff[pos].(LinearizedMap))
-						if err != nil {
+						diffMap, ok := diff[pos].(LinearizedMap)
+						if !ok {
+							return fmt.Errorf("field %d: expected map in diff but got %T", pos, diff[pos])
+						}
+						if err := mergeMaps(maskValue.Masks, nestedVal, diffMap, nested); err != nil {
 							return err
 						}
 					}
@@ -36,7 +161,7 @@ func Merge(mask *UpdateMask, current LinearizedObject, diff LinearizedObject) er
 			} else {
 				if diffVal, exists := diff[pos]; exists {
 					// Update the current object with the value from the diff
-					current[pos] = diffVal
+					current[pos] = nested.overlay(current[pos], diffVal)
 				}
 			}
 
@@ -50,14 +175,14 @@ func Merge(mask *UpdateMask, current LinearizedObject, diff LinearizedObject) er
 }
 
 // mergeSlices merges two LinearizedSlice types using the update mask
-func mergeSlices(mask *UpdateMask, current, diff LinearizedSlice) error {
+func mergeSlices(mask *UpdateMask, current, diff LinearizedSlice, scope fieldScope) error {
 	// Apply operations based on the mask
 	for pos, maskValue := range mask.Values {
 		switch maskValue.Op {
 		case UpdateMaskOperation_ADD:
 			// For ADD, apply the diff if it exists
 			if diffVal, exists := diff[pos]; exists {
-				current[pos] = diffVal
+				current[pos] = scope.overlay(current[pos], diffVal)
 			}
 		case UpdateMaskOperation_REMOVE:
 			// For REMOVE, delete the value at the specified position
@@ -65,7 +190,7 @@ func mergeSlices(mask *UpdateMask, current, diff LinearizedSlice) error {
 		case UpdateMaskOperation_UPDATE:
 			// For UPDATE, apply the diff if it exists
 			if diffVal, exists := diff[pos]; exists {
-				current[pos] = diffVal
+				current[pos] = scope.overlay(current[pos], diffVal)
 			}
 		}
 	}
@@ -73,14 +198,14 @@ func mergeSlices(mask *UpdateMask, current, diff LinearizedSlice) error {
 }
 
 // mergeMaps merges two LinearizedMap types using the update mask
-func mergeMaps(mask *UpdateMask, current, diff LinearizedMap) error {
+func mergeMaps(mask *UpdateMask, current, diff LinearizedMap, scope fieldScope) error {
 	// Apply operations based on the mask
 	for pos, maskValue := range mask.Values {
 		switch maskValue.Op {
 		case UpdateMaskOperation_ADD:
 			// For ADD, apply the diff if it exists
 			if diffVal, exists := diff[pos]; exists {
-				current[pos] = diffVal
+				current[pos] = scope.overlay(current[pos], diffVal).([2]any)
 			}
 		case UpdateMaskOperation_REMOVE:
 			// For REMOVE, delete the key from the current map
@@ -88,7 +213,7 @@ func mergeMaps(mask *UpdateMask, current, diff LinearizedMap) error {
 		case UpdateMaskOperation_UPDATE:
 			// For UPDATE, apply the diff if it exists
 			if diffVal, exists := diff[pos]; exists {
-				current[pos] = diffVal
+				current[pos] = scope.overlay(current[pos], diffVal).([2]any)
 			}
 		}
 	}
@@ -0,0 +1,256 @@
+package linearize
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MergeInto applies the UpdateMask operations to a message in place through protoreflect. It has the
+// same effect as linearizing the message, calling Merge with the same options and unlinearizing the
+// result, but only visits the fields named by the mask. Map positions refer to the entries in the order
+// set by WithMapKeyOrder. Fields marked (linearize.field).diff_ignore are never touched. When a check
+// fails the message is left unchanged.
+func MergeInto(msg proto.Message, mask *UpdateMask, diff LinearizedObject, opts ...MergeOption) error {
+	return MergeIntoContext(context.Background(), msg, mask, diff, opts...)
+}
+
+// MergeIntoContext applies a patch like MergeInto, checking the context at every field it merges. Once
+// the context is done it returns the context's error and leaves the message unchanged.
+func MergeIntoContext(ctx context.Context, msg proto.Message, mask *UpdateMask, diff LinearizedObject, opts ...MergeOption) error {
+	if err := findRedacted(mask, diff); err != nil {
+		return err
+	}
+	options := newMergeOptions(opts)
+	if err := options.limits.check(ctx, diff); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if mask == nil {
+		return nil
+	}
+	scope := newFieldScope(append(descriptorFilters(msg.ProtoReflect().Descriptor()), options.filters...))
+	if len(options.checks) == 0 && ctx.Done() == nil {
+		return options.mergeIntoMessage(ctx, msg.ProtoReflect(), mask, diff, scope)
+	}
+
+	// Merge into a copy so a rejected patch or a cancellation leaves no partial changes behind
+	merged := proto.Clone(msg)
+	if err := options.mergeIntoMessage(ctx, merged.ProtoReflect(), mask, diff, scope); err != nil {
+		return err
+	}
+	obj, err := Linearize(merged, WithMapKeyOrder(options.mapKeyOrder))
+	if err != nil {
+		return err
+	}
+	for _, check := range options.checks {
+		if err := check(obj); err != nil {
+			return err
+		}
+	}
+	proto.Reset(msg)
+	proto.Merge(msg, merged)
+	return nil
+}
+
+// mergeIntoMessage applies a mask to the fields of a message that are in scope.
+func (o *mergeOptions) mergeIntoMessage(ctx context.Context, msg protoreflect.Message, mask *UpdateMask, diff LinearizedObject, scope fieldScope) error {
+	fields := msg.Descriptor().Fields()
+	for _, pos := range sortedMaskKeys(mask) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		maskValue := mask.Values[pos]
+		nested, ok := scope.field(pos)
+		if !ok {
+			// Filtered fields are never touched
+			continue
+		}
+		fd := fields.ByNumber(protoreflect.FieldNumber(pos))
+		if fd == nil {
+			return fmt.Errorf("field number %d not found in the message", pos)
+		}
+
+		switch maskValue.Op {
+		case UpdateMaskOperation_REMOVE:
+			msg.Clear(fd)
+
+		case UpdateMaskOperation_ADD, UpdateMaskOperation_UPDATE:
+			var err error
+			switch {
+			case maskValue.Masks == nil:
+				// Whole values are set the same way Unlinearize sets them
+				if diffVal, exists := diff[pos]; exists {
+					if len(nested) > 0 {
+						var current any
+						if current, err = o.currentValue(msg, fd); err != nil {
+							return err
+						}
+						diffVal = nested.overlay(current, diffVal)
+					}
+					err = unlinearizeMessage(LinearizedObject{pos: diffVal}, msg)
+				}
+			case fd.IsList():
+				diffSlice, ok := diff[pos].(LinearizedSlice)
+				if !ok {
+					return fmt.Errorf("field %s: expected slice in diff but got %T", fd.Name(), diff[pos])
+				}
+				err = o.mergeIntoList(msg, fd, maskValue.Masks, diffSlice, nested)
+			case fd.IsMap():
+				diffMap, ok := diff[pos].(LinearizedMap)
+				if !ok {
+					return fmt.Errorf("field %s: expected map in diff but got %T", fd.Name(), diff[pos])
+				}
+				err = o.mergeIntoMap(msg, fd, maskValue.Masks, diffMap, nested)
+			case fd.Message() != nil:
+				diffObj, ok := diff[pos].(LinearizedObject)
+				if !ok {
+					return fmt.Errorf("field %s: expected object in diff but got %T", fd.Name(), diff[pos])
+				}
+				err = o.mergeIntoMessage(ctx, msg.Mutable(fd).Message(), maskValue.Masks, diffObj, nested)
+			default:
+				err = unlinearizeMessage(LinearizedObject{pos: diff[pos]}, msg)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to merge field %s: %w", fd.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// currentValue returns the linearized value of a field, or nil when it is not set.
+func (o *mergeOptions) currentValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor) (any, error) {
+	if !msg.Has(fd) {
+		return nil, nil
+	}
+	linearizer := linearizeOptions{mapKeyOrder: o.mapKeyOrder}
+	return linearizer.linearizeField(fd, msg.Get(fd), nil, 1)
+}
+
+// mergeIntoList replaces, appends and removes list elements by index, like mergeSlices. Masks with a
+// ListKey are rebuilt by key like mergeKeyedSlices.
+func (o *mergeOptions) mergeIntoList(msg protoreflect.Message, fd protoreflect.FieldDescriptor, mask *UpdateMask, diff LinearizedSlice, scope fieldScope) error {
+	var current LinearizedSlice
+	keyed := mask.GetListKey() != 0
+	if keyed || len(scope) > 0 {
+		value, err := o.currentValue(msg, fd)
+		if err != nil {
+			return err
+		}
+		current, _ = value.(LinearizedSlice)
+	}
+	if keyed {
+		if current == nil {
+			current = make(LinearizedSlice)
+		}
+		if err := mergeKeyedSlices(mask, current, diff, scope); err != nil {
+			return err
+		}
+		return unlinearizeMessage(LinearizedObject{int32(fd.Number()): current}, msg)
+	}
+
+	list := msg.Mutable(fd).List()
+	removed := make(map[int]bool)
+	for _, index := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[index]
+		if maskValue.Op == UpdateMaskOperation_REMOVE {
+			removed[int(index)] = true
+			continue
+		}
+
+		diffElem, exists := diff[index]
+		if !exists {
+			continue
+		}
+		if int(index) > list.Len() {
+			return fmt.Errorf("index %d is past the end of the list", index)
+		}
+		if len(scope) > 0 {
+			diffElem = scope.overlay(current[index], diffElem)
+		}
+		elem, err := unlinearizeElement(list.NewElement(), diffElem, fd)
+		if err != nil {
+			return fmt.Errorf("failed to set list element at index %d: %w", index, err)
+		}
+		if int(index) == list.Len() {
+			list.Append(elem)
+		} else {
+			list.Set(int(index), elem)
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+	kept := make([]protoreflect.Value, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if !removed[i] {
+			kept = append(kept, list.Get(i))
+		}
+	}
+	list.Truncate(0)
+	for _, elem := range kept {
+		list.Append(elem)
+	}
+	return nil
+}
+
+// mergeIntoMap replaces and removes map entries by position, like mergeMaps. Positions refer to
+// the entries in the key order used by Linearize before the merge.
+func (o *mergeOptions) mergeIntoMap(msg protoreflect.Message, fd protoreflect.FieldDescriptor, mask *UpdateMask, diff LinearizedMap, scope fieldScope) error {
+	var current LinearizedMap
+	if len(scope) > 0 {
+		value, err := o.currentValue(msg, fd)
+		if err != nil {
+			return err
+		}
+		current, _ = value.(LinearizedMap)
+	}
+
+	m := msg.Mutable(fd).Map()
+	var keys []protoreflect.MapKey
+	m.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		return o.mapKeyOrder.less(keys[i], keys[j])
+	})
+
+	// Entries are removed before they are set, so a key that moves position is not lost
+	var updates []int32
+	for _, position := range sortedMaskKeys(mask) {
+		if int(position) < len(keys) {
+			m.Clear(keys[position])
+		}
+		if mask.Values[position].Op != UpdateMaskOperation_REMOVE {
+			updates = append(updates, position)
+		}
+	}
+
+	for _, position := range updates {
+		entry, exists := diff[position]
+		if !exists {
+			continue
+		}
+		if len(scope) > 0 {
+			entry = scope.overlay(current[position], entry).([2]any)
+		}
+		key, err := scalarValue(entry[0], fd.MapKey())
+		if err != nil {
+			return fmt.Errorf("failed to set map key %v: %w", entry[0], err)
+		}
+		value, err := unlinearizeElement(m.NewValue(), entry[1], fd.MapValue())
+		if err != nil {
+			return fmt.Errorf("failed to set map value for key %v: %w", entry[0], err)
+		}
+		m.Set(key.MapKey(), value)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package linearize
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMergeInto(t *testing.T) {
+	changes := map[string]func(msg *mocks.SuperComplex){
+		"scalar": func(msg *mocks.SuperComplex) {
+			msg.Field1 = "changed"
+		},
+		"nested scalar": func(msg *mocks.SuperComplex) {
+			msg.Nested.Nested.Field2 = 7
+		},
+		"removed message": func(msg *mocks.SuperComplex) {
+			msg.Nested = nil
+		},
+		"appended element": func(msg *mocks.SuperComplex) {
+			msg.Repeated = append(msg.Repeated, &mocks.Complex{Field1: "appended"})
+		},
+		"removed element": func(msg *mocks.SuperComplex) {
+			msg.Nested.Nested.Repeated = msg.Nested.Nested.Repeated[:1]
+		},
+		"changed element": func(msg *mocks.SuperComplex) {
+			msg.Repeated[0].Nested.Field1 = "changed"
+		},
+		"added map entry": func(msg *mocks.SuperComplex) {
+			msg.Nested.Map["key0"] = &mocks.Simple{Field1: "first"}
+		},
+		"removed map entry": func(msg *mocks.SuperComplex) {
+			delete(msg.Nested.Map, "key1")
+		},
+		"changed map entry": func(msg *mocks.SuperComplex) {
+			msg.Map[2].Field2 = 1
+		},
+	}
+
+	for name, change := range changes {
+		t.Run("should match merge for "+name, func(t *testing.T) {
+			// Arrange
+			previous := mocks.CreateSuperComplexMessage()
+			latest := mocks.CreateSuperComplexMessage()
+			change(latest)
+			patch, err := DiffMessages(previous, latest)
+			require.NoError(t, err)
+
+			// Act
+			err = MergeInto(previous, patch.Mask, patch.After)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(latest, previous), "got %v", previous)
+		})
+	}
+
+	t.Run("should leave the message unchanged for an empty patch", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+
+		// Act
+		err := MergeInto(msg, nil, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(mocks.CreateSuperComplexMessage(), msg))
+	})
+
+	t.Run("should reject redacted values", func(t *testing.T) {
+		// Arrange
+		msg := &mocks.Annotated{}
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{6: {Op: UpdateMaskOperation_UPDATE}}}
+		diff := LinearizedObject{6: Redacted{}}
+
+		// Act
+		err := MergeInto(msg, mask, diff)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRedacted)
+	})
+
+	t.Run("should fail on a mismatched diff", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{
+			3: {Op: UpdateMaskOperation_UPDATE, Masks: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+				1: {Op: UpdateMaskOperation_UPDATE},
+			}}},
+		}}
+		diff := LinearizedObject{3: "not an object"}
+
+		// Act
+		err := MergeInto(msg, mask, diff)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("should leave filtered fields untouched", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+		latest.Field1 = "changed"
+		latest.Nested.Field2 = 7
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+		filter, err := ExcludeFields(previous.ProtoReflect().Descriptor(), "Nested.Field2")
+		require.NoError(t, err)
+
+		// Act
+		err = MergeInto(previous, patch.Mask, patch.After, filter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "changed", previous.Field1)
+		assert.Equal(t, mocks.CreateSuperComplexMessage().Nested.Field2, previous.Nested.Field2)
+	})
+
+	t.Run("should leave the message unchanged when a check fails", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+		latest.Field1 = "changed"
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+		rejected := fmt.Errorf("rejected")
+		check := WithCheck(func(merged LinearizedObject) error {
+			if merged[1] == "changed" {
+				return rejected
+			}
+			return nil
+		})
+
+		// Act
+		err = MergeInto(previous, patch.Mask, patch.After, check)
+
+		// Assert
+		assert.ErrorIs(t, err, rejected)
+		assert.True(t, proto.Equal(mocks.CreateSuperComplexMessage(), previous))
+	})
+
+	t.Run("should reject a diff over the limits", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+		latest.Repeated = append(latest.Repeated, &mocks.Complex{Field1: "appended"})
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+
+		// Act
+		err = MergeInto(previous, patch.Mask, patch.After, WithLimits(Limits{MaxNodes: 1}))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrTooLarge)
+		assert.True(t, proto.Equal(mocks.CreateSuperComplexMessage(), previous))
+	})
+
+	t.Run("should address map entries in natural key order", func(t *testing.T) {
+		// Arrange
+		previous := &mocks.SuperComplex{Map: map[int32]*mocks.Complex{
+			2:  {Field1: "two"},
+			10: {Field1: "ten"},
+		}}
+		latest := &mocks.SuperComplex{Map: map[int32]*mocks.Complex{
+			2:  {Field1: "changed"},
+			10: {Field1: "ten"},
+		}}
+		order := WithMapKeyOrder(MapKeysNatural)
+		prevObj, err := Linearize(previous, order)
+		require.NoError(t, err)
+		latestObj, err := Linearize(latest, order)
+		require.NoError(t, err)
+		_, after, mask, err := Diff(prevObj, latestObj)
+		require.NoError(t, err)
+
+		// Act
+		err = MergeInto(previous, mask, after, order)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(latest, previous), "got %v", previous)
+	})
+}
+
+// largeMessage returns a message with many repeated elements and map entries.
+func largeMessage() *mocks.SuperComplex {
+	msg := mocks.CreateSuperComplexMessage()
+	for i := 0; i < 1000; i++ {
+		msg.Repeated = append(msg.Repeated, mocks.CreateComplexMessage())
+		msg.Map[int32(i+10)] = mocks.CreateComplexMessage()
+		msg.Nested.Map[fmt.Sprintf("key%04d", i)] = mocks.CreateSimpleMessage()
+	}
+	return msg
+}
+
+func smallPatch(b *testing.B) Patch {
+	latest := largeMessage()
+	latest.Field1 = "changed"
+	latest.Repeated[500].Field2 = 1
+	patch, err := DiffMessages(largeMessage(), latest)
+	require.NoError(b, err)
+	return patch
+}
+
+func BenchmarkMergeRoundTrip(b *testing.B) {
+	patch := smallPatch(b)
+	msg := largeMessage()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ApplyPatch(msg, patch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMergeInto(b *testing.B) {
+	patch := smallPatch(b)
+	msg := largeMessage()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := MergeInto(msg, patch.Mask, patch.After); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package linearize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"sort"
+)
+
+// MerkleNode caches the hash of a linearized value together with the nodes of its children.
+// Children are keyed like the value itself: by field number for objects, by index for slices
+// and by position for maps. Map entry nodes hold the key at 0 and the value at 1.
+// Scalars have no children.
+type MerkleNode struct {
+	Hash     [32]byte
+	Children map[int32]*MerkleNode
+}
+
+// NewMerkleTree hashes a linearized value bottom-up. The root hash equals the value's Hash,
+// so values that are Equal have identical roots. After the value changes, Update hashes the
+// changed path again.
+func NewMerkleTree(value any) *MerkleNode {
+	node := &MerkleNode{}
+	switch v := value.(type) {
+	case LinearizedObject:
+		node.Children = newMerkleChildren(v)
+	case LinearizedSlice:
+		node.Children = newMerkleChildren(v)
+	case LinearizedMap:
+		node.Children = make(map[int32]*MerkleNode, len(v))
+		for position, entry := range v {
+			node.Children[position] = NewMerkleTree(entry)
+		}
+	case [2]any:
+		node.Children = map[int32]*MerkleNode{0: NewMerkleTree(v[0]), 1: NewMerkleTree(v[1])}
+	}
+	node.rehash(value)
+	return node
+}
+
+// newMerkleChildren hashes the children of an object or slice.
+func newMerkleChildren(entries map[int32]any) map[int32]*MerkleNode {
+	children := make(map[int32]*MerkleNode, len(entries))
+	for key, value := range entries {
+		children[key] = NewMerkleTree(value)
+	}
+	return children
+}
+
+// Update hashes the tree again after the value at the path was set or deleted in value, the value
+// the tree was built from. Only the nodes along the path and below it are hashed, so a small change
+// to a large value is cheap. Slices and maps whose length changed are hashed again as a whole,
+// since their elements and entries moved.
+func (n *MerkleNode) Update(value any, path Path) error {
+	if len(path) == 0 {
+		*n = *NewMerkleTree(value)
+		return nil
+	}
+	next, exists, err := child(value, path[0])
+	if err != nil {
+		return &PathError{Op: "update", Path: path[:1], Err: err}
+	}
+	if n.Children == nil {
+		// The value was a scalar when the tree was built
+		*n = *NewMerkleTree(value)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case LinearizedObject:
+		if !exists {
+			delete(n.Children, path[0].Field)
+			break
+		}
+		if err := n.updateChild(path[0].Field, next, path); err != nil {
+			return err
+		}
+	case LinearizedSlice:
+		if len(v) != len(n.Children) {
+			*n = *NewMerkleTree(value)
+			return nil
+		}
+		if err := n.updateChild(path[0].Index, next, path); err != nil {
+			return err
+		}
+	case LinearizedMap:
+		// Entries are updated in place as long as the entry at the key's position still holds the key
+		position := mapPosition(v, path[0].Key)
+		entry := n.Child(position)
+		if position < 0 || len(v) != len(n.Children) || entry == nil || entry.Child(0).Hash != NewMerkleTree(v[position][0]).Hash {
+			*n = *NewMerkleTree(value)
+			return nil
+		}
+		if err := entry.updateChild(1, next, path); err != nil {
+			return err
+		}
+		entry.rehash(v[position])
+	}
+	n.rehash(value)
+	return nil
+}
+
+// updateChild updates the child at key for the rest of the path, creating it when it is missing.
+func (n *MerkleNode) updateChild(key int32, value any, path Path) error {
+	node, exists := n.Children[key]
+	if !exists {
+		n.Children[key] = NewMerkleTree(value)
+		return nil
+	}
+	if err := node.Update(value, path[1:]); err != nil {
+		var pathErr *PathError
+		if errors.As(err, &pathErr) {
+			pathErr.Path = append(Path{path[0]}, pathErr.Path...)
+		}
+		return err
+	}
+	return nil
+}
+
+// rehash computes the hash of the node from the value and the hashes of its children.
+func (n *MerkleNode) rehash(value any) {
+	h := sha256.New()
+	switch v := value.(type) {
+	case LinearizedObject:
+		n.hashContainer(h, 'O', v)
+	case LinearizedSlice:
+		n.hashContainer(h, 'S', v)
+	case LinearizedMap:
+		keys := make(map[int32][]byte, len(v))
+		for position, entry := range v {
+			keys[position] = canonicalBytes(entry[0])
+		}
+
+		// Entries are hashed in key order so the hash does not depend on positions
+		positions := sortedKeys(v)
+		sort.Slice(positions, func(i, j int) bool {
+			return bytes.Compare(keys[positions[i]], keys[positions[j]]) < 0
+		})
+		h.Write([]byte{1, 'M'})
+		for _, position := range positions {
+			h.Write(n.Children[position].Hash[:])
+		}
+	case [2]any:
+		h.Write([]byte{1, 'E'})
+		h.Write(n.Children[0].Hash[:])
+		h.Write(n.Children[1].Hash[:])
+	default:
+		n.Hash = sha256.Sum256(append([]byte{0}, canonicalBytes(value)...))
+		return
+	}
+	h.Sum(n.Hash[:0])
+}
+
+// hashContainer hashes an object or slice from the hashes of its children in key order.
+func (n *MerkleNode) hashContainer(h hash.Hash, tag byte, entries map[int32]any) {
+	h.Write([]byte{1, tag})
+	for _, key := range sortedKeys(entries) {
+		var k [4]byte
+		binary.BigEndian.PutUint32(k[:], uint32(key))
+		h.Write(k[:])
+		h.Write(n.Children[key].Hash[:])
+	}
+}
+
+// Child returns the node for a child key, or nil when the node or child does not exist.
+func (n *MerkleNode) Child(key int32) *MerkleNode {
+	if n == nil {
+		return nil
+	}
+	return n.Children[key]
+}
+
+// ChildHashes returns the hash of each child, which is what a replica sends to a peer
+// when comparing one level of the tree.
+func (n *MerkleNode) ChildHashes() map[int32][32]byte {
+	hashes := make(map[int32][32]byte, len(n.Children))
+	for key, child := range n.Children {
+		hashes[key] = child.Hash
+	}
+	return hashes
+}
+
+// DifferingChildren compares the children of the node with the child hashes received from a peer
+// and returns the keys that differ or exist on only one side, in ascending order.
+func (n *MerkleNode) DifferingChildren(remote map[int32][32]byte) []int32 {
+	var keys []int32
+	for key, child := range n.Children {
+		if hash, exists := remote[key]; !exists || hash != child.Hash {
+			keys = append(keys, key)
+		}
+	}
+	for key := range remote {
+		if _, exists := n.Children[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// MerkleDiff walks two trees top-down, descending only into children whose hashes differ,
+// and returns a mask of the differing leaves. It returns nil when the trees are identical.
+func MerkleDiff(previous, latest *MerkleNode) *UpdateMask {
+	if previous.Hash == latest.Hash {
+		return nil
+	}
+
+	mask := &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+	for _, key := range previous.DifferingChildren(latest.ChildHashes()) {
+		prevChild, latestChild := previous.Children[key], latest.Children[key]
+		switch {
+		case prevChild == nil:
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_ADD}
+		case latestChild == nil:
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_REMOVE}
+		case prevChild.Children == nil || latestChild.Children == nil:
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE}
+		default:
+			mask.Values[key] = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE, Masks: MerkleDiff(prevChild, latestChild)}
+		}
+	}
+	return mask
+}
@@ -0,0 +1,164 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkle(t *testing.T) {
+	md := (&mocks.SuperComplex{}).ProtoReflect().Descriptor()
+
+	linearizePair := func(t *testing.T) (LinearizedObject, LinearizedObject) {
+		linearized1, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+
+		msg2 := mocks.CreateSuperComplexMessage()
+		msg2.Nested.Map["key2"].Field1 = "changed"
+		msg2.Repeated[0].Nested.Field2 = 7
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+		return linearized1, linearized2
+	}
+
+	t.Run("should match hash of value", func(t *testing.T) {
+		// Arrange
+		linearized, _ := linearizePair(t)
+
+		// Act
+		tree := NewMerkleTree(linearized)
+
+		// Assert
+		assert.Equal(t, linearized.Hash(), tree.Hash)
+		assert.Equal(t, linearized[3].(LinearizedObject).Hash(), tree.Child(3).Hash)
+	})
+
+	t.Run("should diff the same as without trees", func(t *testing.T) {
+		// Arrange
+		linearized1, linearized2 := linearizePair(t)
+		expectedBefore, expectedAfter, expectedMask, err := Diff(linearized1, linearized2)
+		require.NoError(t, err)
+
+		// Act
+		before, after, mask, err := DiffMerkle(linearized1, linearized2, NewMerkleTree(linearized1), NewMerkleTree(linearized2))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, Paths(expectedMask, md), Paths(mask, md))
+		assert.True(t, expectedBefore.Equal(before))
+		assert.True(t, expectedAfter.Equal(after))
+	})
+
+	t.Run("should skip identical documents", func(t *testing.T) {
+		// Arrange
+		linearized, _ := linearizePair(t)
+		tree := NewMerkleTree(linearized)
+
+		// Act
+		before, after, mask, err := DiffMerkle(linearized, linearized, tree, tree)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, before)
+		assert.Nil(t, after)
+		assert.Nil(t, mask)
+	})
+
+	t.Run("should find differences top-down", func(t *testing.T) {
+		// Arrange
+		linearized1, linearized2 := linearizePair(t)
+		local, remote := NewMerkleTree(linearized1), NewMerkleTree(linearized2)
+
+		// Act
+		differing := local.DifferingChildren(remote.ChildHashes())
+		mask := MerkleDiff(local, remote)
+
+		// Assert
+		assert.Equal(t, []int32{3, 4}, differing)
+		assert.Equal(t, []string{`Nested.Map["key2"].Field1`, "Repeated[0].Nested.Field2"}, Paths(mask, md, linearized1, linearized2))
+		assert.Nil(t, MerkleDiff(local, local))
+	})
+
+	changes := map[string]func(t *testing.T, obj LinearizedObject) Path{
+		"changed scalar": func(t *testing.T, obj LinearizedObject) Path {
+			path := Path{}.Field(3).Field(3).Field(1)
+			require.NoError(t, obj.Set(path, "changed"))
+			return path
+		},
+		"changed map value": func(t *testing.T, obj LinearizedObject) Path {
+			path := Path{}.Field(3).Field(5).Key("key2").Field(2)
+			require.NoError(t, obj.Set(path, int32(7)))
+			return path
+		},
+		"added map entry": func(t *testing.T, obj LinearizedObject) Path {
+			path := Path{}.Field(3).Field(5).Key("key0")
+			require.NoError(t, obj.Set(path, LinearizedObject{1: "first"}))
+			return path
+		},
+		"deleted element": func(t *testing.T, obj LinearizedObject) Path {
+			path := Path{}.Field(4).Index(0)
+			require.NoError(t, obj.Delete(path))
+			return path
+		},
+		"deleted field": func(t *testing.T, obj LinearizedObject) Path {
+			path := Path{}.Field(3).Field(3)
+			require.NoError(t, obj.Delete(path))
+			return path
+		},
+		"created object": func(t *testing.T, obj LinearizedObject) Path {
+			path := Path{}.Field(7).Field(1)
+			require.NoError(t, obj.Set(path, "created"))
+			return path
+		},
+	}
+
+	for name, change := range changes {
+		t.Run("should update the tree for a "+name, func(t *testing.T) {
+			// Arrange
+			linearized, _ := linearizePair(t)
+			tree := NewMerkleTree(linearized)
+			path := change(t, linearized)
+
+			// Act
+			err := tree.Update(linearized, path)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, NewMerkleTree(linearized), tree)
+		})
+	}
+
+	t.Run("should only hash the updated path", func(t *testing.T) {
+		// Arrange
+		linearized, _ := linearizePair(t)
+		tree := NewMerkleTree(linearized)
+		sibling := tree.Child(4)
+		path := Path{}.Field(3).Field(3).Field(1)
+		require.NoError(t, linearized.Set(path, "changed"))
+
+		// Act
+		err := tree.Update(linearized, path)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Same(t, sibling, tree.Child(4))
+		assert.Equal(t, linearized.Hash(), tree.Hash)
+	})
+
+	t.Run("should reject paths that do not fit the value", func(t *testing.T) {
+		// Arrange
+		linearized, _ := linearizePair(t)
+		tree := NewMerkleTree(linearized)
+
+		// Act
+		err := tree.Update(linearized, Path{}.Field(3).Index(0))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrPathType)
+		var pathErr *PathError
+		require.ErrorAs(t, err, &pathErr)
+		assert.Equal(t, "3[0]", pathErr.Path.String())
+	})
+}
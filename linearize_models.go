@@ -8,3 +8,18 @@ type LinearizedSlice map[int32]any
 
 // LinearizedMap is a map of any keys to any values (used for Protobuf map fields)
 type LinearizedMap map[int32][2]any
+
+// Patch is a Diff result that can be stored, transferred and applied with Merge
+type Patch struct {
+	Mask   *UpdateMask
+	Before LinearizedObject
+	After  LinearizedObject
+}
+
+// Apply merges the patch into the current LinearizedObject
+func (p *Patch) Apply(current LinearizedObject, opts ...MergeOption) error {
+	if p.Mask == nil {
+		return nil
+	}
+	return Merge(p.Mask, current, p.After, opts...)
+}
@@ -0,0 +1,148 @@
+package linearize
+
+import (
+	"sync"
+
+	"github.com/fgrzl/linearize/options"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DescriptorOption makes Diff and Merge honor the (linearize.field) options declared in a message
+// descriptor: diff_ignore fields are left out of masks and never touched by Merge, atomic messages are
+// compared as single values, list_key matches repeated elements by key, float_tolerance ignores small
+// float changes and sensitive fields are redacted in the before and after objects like WithRedaction
+// does with a per-process salt. It implements DiffOption and MergeOption. Linearize, DiffMessages,
+// MergeInto and ApplyPatch read the options from the message without it. Masks of repeated fields
+// matched by key carry the key in ListKey, so Merge needs no descriptor to apply them.
+type DescriptorOption struct {
+	md protoreflect.MessageDescriptor
+}
+
+// WithDescriptor returns an option describing the objects passed to Diff or Merge.
+func WithDescriptor(md protoreflect.MessageDescriptor) DescriptorOption {
+	return DescriptorOption{md: md}
+}
+
+func (o DescriptorOption) applyDiff(opts *diffOptions) {
+	opts.md = o.md
+	opts.filters = append(opts.filters, descriptorFilters(o.md)...)
+	if opts.redactMD == nil {
+		opts.redactMD = o.md
+	}
+}
+
+func (o DescriptorOption) applyMerge(opts *mergeOptions) {
+	opts.filters = append(opts.filters, descriptorFilters(o.md)...)
+}
+
+// ignoredFields returns a filter dropping the fields marked diff_ignore.
+func ignoredFields(md protoreflect.MessageDescriptor) *FieldFilter {
+	return FilterFields(md, func(fd protoreflect.FieldDescriptor) bool {
+		return !fieldOptions(fd).GetDiffIgnore()
+	})
+}
+
+// descriptorFilters returns the filters honoring the options of the message described by md, or
+// nil when no field at any depth is marked diff_ignore.
+func descriptorFilters(md protoreflect.MessageDescriptor) []*FieldFilter {
+	if !ignoresFields(md) {
+		return nil
+	}
+	return []*FieldFilter{ignoredFields(md)}
+}
+
+// listKey returns the field number identifying the elements of a repeated message field with a
+// list_key, or false when the field has none.
+func listKey(fd protoreflect.FieldDescriptor) (int32, bool) {
+	name := fieldOptions(fd).GetListKey()
+	if name == "" || !fd.IsList() || fd.Message() == nil {
+		return 0, false
+	}
+	keyField := fd.Message().Fields().ByName(protoreflect.Name(name))
+	if keyField == nil {
+		return 0, false
+	}
+	return int32(keyField.Number()), true
+}
+
+// Options are cached by full name, so descriptors built at run time do not grow the caches. Each
+// entry remembers its descriptor and is replaced when another descriptor of the same name is used.
+var (
+	fieldOptionsCache    sync.Map // protoreflect.FullName -> cachedFieldOptions
+	ignoresFieldsCache   sync.Map // protoreflect.FullName -> cachedFound
+	sensitiveFieldsCache sync.Map // protoreflect.FullName -> cachedFound
+)
+
+type cachedFieldOptions struct {
+	fd   protoreflect.FieldDescriptor
+	opts *options.FieldOptions
+}
+
+type cachedFound struct {
+	md    protoreflect.MessageDescriptor
+	found bool
+}
+
+// fieldOptions returns the (linearize.field) options of a field, or nil when it has none.
+func fieldOptions(fd protoreflect.FieldDescriptor) *options.FieldOptions {
+	if fd == nil {
+		return nil
+	}
+	if cached, ok := fieldOptionsCache.Load(fd.FullName()); ok && cached.(cachedFieldOptions).fd == fd {
+		return cached.(cachedFieldOptions).opts
+	}
+
+	var opts *options.FieldOptions
+	if fieldOpts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && fieldOpts != nil && proto.HasExtension(fieldOpts, options.E_Field) {
+		opts = proto.GetExtension(fieldOpts, options.E_Field).(*options.FieldOptions)
+	}
+	fieldOptionsCache.Store(fd.FullName(), cachedFieldOptions{fd: fd, opts: opts})
+	return opts
+}
+
+// ignoresFields reports whether a field of the message, or of a message nested in it, is marked diff_ignore.
+func ignoresFields(md protoreflect.MessageDescriptor) bool {
+	return cachedFind(&ignoresFieldsCache, md, func(fd protoreflect.FieldDescriptor) bool {
+		return fieldOptions(fd).GetDiffIgnore()
+	})
+}
+
+// hasSensitiveFields reports whether a field of the message, or of a message nested in it, is sensitive.
+func hasSensitiveFields(md protoreflect.MessageDescriptor) bool {
+	return cachedFind(&sensitiveFieldsCache, md, IsSensitive)
+}
+
+// cachedFind reports whether findField finds a matching field, caching the answer by message name.
+func cachedFind(cache *sync.Map, md protoreflect.MessageDescriptor, match func(fd protoreflect.FieldDescriptor) bool) bool {
+	if cached, ok := cache.Load(md.FullName()); ok && cached.(cachedFound).md == md {
+		return cached.(cachedFound).found
+	}
+	found := findField(md, match, make(map[protoreflect.FullName]bool))
+	cache.Store(md.FullName(), cachedFound{md: md, found: found})
+	return found
+}
+
+func findField(md protoreflect.MessageDescriptor, match func(fd protoreflect.FieldDescriptor) bool, visited map[protoreflect.FullName]bool) bool {
+	if visited[md.FullName()] {
+		return false
+	}
+	visited[md.FullName()] = true
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if match(fd) {
+			return true
+		}
+		nested := fd.Message()
+		if fd.IsMap() {
+			nested = fd.MapValue().Message()
+		}
+		if nested != nil && findField(nested, match, visited) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFieldOptions(t *testing.T) {
+	md := (&mocks.Annotated{}).ProtoReflect().Descriptor()
+
+	createAnnotated := func() *mocks.Annotated {
+		return &mocks.Annotated{
+			Name:        "annotated",
+			UpdatedAt:   1000,
+			Address:     &mocks.Simple{Field1: "street", Field2: 1},
+			Items:       []*mocks.Keyed{{Id: "a", Value: "first"}, {Id: "b", Value: "second"}},
+			Temperature: 20.5,
+			Secret:      "hunter2",
+		}
+	}
+
+	// Linearize leaves diff_ignore fields out, so messages merged through objects lose them
+	withoutIgnored := func(msg *mocks.Annotated) *mocks.Annotated {
+		msg = proto.Clone(msg).(*mocks.Annotated)
+		msg.UpdatedAt = 0
+		return msg
+	}
+
+	diffAnnotated := func(t *testing.T, latest *mocks.Annotated) (LinearizedObject, LinearizedObject, LinearizedObject, *UpdateMask) {
+		previous, err := Linearize(createAnnotated())
+		require.NoError(t, err)
+		linearized, err := Linearize(latest)
+		require.NoError(t, err)
+		before, after, mask, err := Diff(previous, linearized, WithDescriptor(md))
+		require.NoError(t, err)
+		return previous, before, after, mask
+	}
+
+	t.Run("should ignore diff_ignore fields and small float changes", func(t *testing.T) {
+		// Arrange
+		latest := createAnnotated()
+		latest.UpdatedAt = 2000
+		latest.Temperature = 20.505
+
+		// Act
+		_, _, _, mask := diffAnnotated(t, latest)
+
+		// Assert
+		assert.Nil(t, mask)
+	})
+
+	t.Run("should report float changes beyond the tolerance", func(t *testing.T) {
+		// Arrange
+		latest := createAnnotated()
+		latest.Temperature = 20.6
+
+		// Act
+		_, _, _, mask := diffAnnotated(t, latest)
+
+		// Assert
+		assert.Equal(t, []string{"Temperature"}, Paths(mask, md))
+	})
+
+	t.Run("should replace atomic messages as a whole", func(t *testing.T) {
+		// Arrange
+		latest := createAnnotated()
+		latest.Address.Field2 = 2
+
+		// Act
+		_, _, after, mask := diffAnnotated(t, latest)
+
+		// Assert
+		assert.Equal(t, []string{"Address"}, Paths(mask, md))
+		assert.Equal(t, LinearizedObject{1: "street", 2: int32(2)}, after[3])
+	})
+
+	t.Run("should match list elements by key", func(t *testing.T) {
+		// Arrange
+		latest := createAnnotated()
+		latest.Items[0].Value = "changed"
+		latest.Items[1] = &mocks.Keyed{Id: "c", Value: "second"}
+
+		// Act
+		_, _, _, mask := diffAnnotated(t, latest)
+
+		// Assert
+		assert.Equal(t, []string{"Items[0].Value", "Items[1]", "Items[2]"}, Paths(mask, md))
+		assert.Equal(t, UpdateMaskOperation_REMOVE, mask.Values[4].Masks.Values[1].Op)
+		assert.Equal(t, UpdateMaskOperation_ADD, mask.Values[4].Masks.Values[2].Op)
+	})
+
+	t.Run("should report an element inserted in the middle of a keyed list once", func(t *testing.T) {
+		// Arrange
+		latest := createAnnotated()
+		latest.Items = []*mocks.Keyed{latest.Items[0], {Id: "x", Value: "inserted"}, latest.Items[1]}
+		latest.Items[2].Value = "changed"
+
+		// Act
+		previous, before, after, mask := diffAnnotated(t, latest)
+
+		// Assert
+		assert.Equal(t, []string{"Items[1]", "Items[2].Value"}, Paths(mask, md))
+		assert.Equal(t, UpdateMaskOperation_ADD, mask.Values[4].Masks.Values[1].Op)
+
+		require.NoError(t, Merge(mask, previous, after, WithDescriptor(md)))
+		var merged mocks.Annotated
+		require.NoError(t, Unlinearize(previous, &merged))
+		assert.True(t, proto.Equal(withoutIgnored(latest), &merged))
+		assert.Equal(t, "second", before[4].(LinearizedSlice)[1].(LinearizedObject)[2])
+	})
+
+	t.Run("should merge removed and reordered keyed elements", func(t *testing.T) {
+		// Arrange
+		previousMsg := createAnnotated()
+		previousMsg.Items = append(previousMsg.Items, &mocks.Keyed{Id: "c", Value: "third"}, &mocks.Keyed{Id: "d", Value: "fourth"})
+		latest := createAnnotated()
+		latest.Items = []*mocks.Keyed{{Id: "d", Value: "fourth"}, {Id: "a", Value: "first"}, {Id: "c", Value: "changed"}}
+		current, err := Linearize(previousMsg)
+		require.NoError(t, err)
+		linearized, err := Linearize(latest)
+		require.NoError(t, err)
+		_, after, mask, err := Diff(current, linearized, WithDescriptor(md))
+		require.NoError(t, err)
+
+		// Act
+		err = Merge(mask, current, after, WithDescriptor(md))
+
+		// Assert
+		require.NoError(t, err)
+		var merged mocks.Annotated
+		require.NoError(t, Unlinearize(current, &merged))
+		assert.True(t, proto.Equal(withoutIgnored(latest), &merged))
+	})
+
+	t.Run("should merge keyed lists that share no elements without the descriptor", func(t *testing.T) {
+		// Arrange
+		previousMsg := createAnnotated()
+		previousMsg.Items = previousMsg.Items[:1]
+		latest := createAnnotated()
+		latest.Items = []*mocks.Keyed{{Id: "b", Value: "second"}}
+		current, err := Linearize(previousMsg)
+		require.NoError(t, err)
+		linearized, err := Linearize(latest)
+		require.NoError(t, err)
+		before, after, mask, err := Diff(current, linearized, WithDescriptor(md))
+		require.NoError(t, err)
+
+		// Act
+		err = Merge(mask, current, after)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), mask.Values[4].Masks.ListKey)
+		var merged, unlinearizedBefore, unlinearizedAfter mocks.Annotated
+		require.NoError(t, Unlinearize(current, &merged))
+		assert.True(t, proto.Equal(withoutIgnored(latest), &merged), "got %v", &merged)
+		require.NoError(t, Unlinearize(LinearizedObject{4: before[4]}, &unlinearizedBefore))
+		assert.Equal(t, "a", unlinearizedBefore.Items[0].Id)
+		require.NoError(t, Unlinearize(LinearizedObject{4: after[4]}, &unlinearizedAfter))
+		assert.Equal(t, "b", unlinearizedAfter.Items[0].Id)
+	})
+
+	t.Run("should leave diff_ignore fields out of linearized objects", func(t *testing.T) {
+		// Act
+		linearized, err := Linearize(createAnnotated())
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotContains(t, linearized, int32(2))
+		assert.Equal(t, "annotated", linearized[1])
+	})
+
+	t.Run("should not merge diff_ignore fields", func(t *testing.T) {
+		// Arrange
+		current := LinearizedObject{1: "annotated", 2: int64(1000)}
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{
+			1: {Op: UpdateMaskOperation_UPDATE},
+			2: {Op: UpdateMaskOperation_UPDATE},
+		}}
+		diff := LinearizedObject{1: "renamed", 2: int64(5)}
+
+		// Act
+		err := Merge(mask, current, diff, WithDescriptor(md))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "renamed", current[1])
+		assert.Equal(t, int64(1000), current[2])
+	})
+
+	t.Run("should honor the options of messages without the descriptor", func(t *testing.T) {
+		// Arrange
+		previous := createAnnotated()
+		latest := createAnnotated()
+		latest.UpdatedAt = 2000
+		latest.Secret = "changed"
+		latest.Items = []*mocks.Keyed{{Id: "b", Value: "second"}, {Id: "a", Value: "first"}}
+
+		// Act
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+		paths := Paths(patch.Mask, md)
+		redacted := patch.After[6]
+		delete(patch.Mask.Values, 6)
+		patched, err := ApplyPatch(previous, patch)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, paths, "Secret")
+		assert.NotContains(t, paths, "UpdatedAt")
+		assert.IsType(t, Redacted{}, redacted)
+		assert.Equal(t, int64(1000), patched.UpdatedAt)
+		assert.Equal(t, "hunter2", patched.Secret)
+		assert.True(t, proto.Equal(latest.Items[0], patched.Items[0]), "got %v", patched.Items)
+		assert.True(t, proto.Equal(latest.Items[1], patched.Items[1]), "got %v", patched.Items)
+	})
+}
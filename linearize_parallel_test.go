@@ -0,0 +1,127 @@
+package linearize
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeObjects linearizes two large messages that differ in top-level, repeated and map fields.
+func largeObjects(t testing.TB) (LinearizedObject, LinearizedObject) {
+	latest := largeMessage()
+	latest.Field1 = "changed"
+	latest.Repeated[10].Field2 = 1
+	latest.Repeated[900].Nested.Field1 = "changed"
+	latest.Repeated = latest.Repeated[:950]
+	latest.Map[500].Field1 = "changed"
+	delete(latest.Map, 700)
+	latest.Nested.Map["key0999"] = &mocks.Simple{Field1: "changed"}
+
+	previous, err := Linearize(largeMessage())
+	require.NoError(t, err)
+	next, err := Linearize(latest)
+	require.NoError(t, err)
+	return previous, next
+}
+
+func TestParallelDiff(t *testing.T) {
+	t.Run("should match the sequential diff", func(t *testing.T) {
+		// Arrange
+		previous, latest := largeObjects(t)
+		expectedBefore, expectedAfter, expectedMask, err := Diff(previous, latest)
+		require.NoError(t, err)
+
+		for _, workers := range []int{2, 4, 16} {
+			// Act
+			before, after, mask, err := Diff(previous, latest, WithParallelism(workers))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expectedBefore, before)
+			assert.Equal(t, expectedAfter, after)
+			assert.Equal(t, expectedMask, mask)
+		}
+	})
+
+	t.Run("should match the sequential diff with a descriptor", func(t *testing.T) {
+		// Arrange
+		previous, latest := largeObjects(t)
+		md := (&mocks.SuperComplex{}).ProtoReflect().Descriptor()
+		expectedBefore, expectedAfter, expectedMask, err := Diff(previous, latest, WithDescriptor(md))
+		require.NoError(t, err)
+
+		// Act
+		before, after, mask, err := Diff(previous, latest, WithDescriptor(md), WithParallelism(8))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expectedBefore, before)
+		assert.Equal(t, expectedAfter, after)
+		assert.Equal(t, expectedMask, mask)
+	})
+
+	t.Run("should report errors found by workers", func(t *testing.T) {
+		// Arrange
+		previous, latest := largeObjects(t)
+
+		// Act
+		_, _, _, err := Diff(previous, latest, WithMaxDepth(2), WithParallelism(4))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrDepthExceeded)
+	})
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		// Arrange
+		previous, latest := largeObjects(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		_, _, _, err := DiffContext(ctx, previous, latest, WithParallelism(4))
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should diff like Diff with an active context", func(t *testing.T) {
+		// Arrange
+		previous, latest := largeObjects(t)
+		expectedBefore, expectedAfter, expectedMask, err := Diff(previous, latest)
+		require.NoError(t, err)
+
+		// Act
+		before, after, mask, err := DiffContext(context.Background(), previous, latest)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expectedBefore, before)
+		assert.Equal(t, expectedAfter, after)
+		assert.Equal(t, expectedMask, mask)
+	})
+}
+
+func BenchmarkDiffSequential(b *testing.B) {
+	previous, latest := largeObjects(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := Diff(previous, latest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDiffParallel(b *testing.B) {
+	previous, latest := largeObjects(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := Diff(previous, latest, WithParallelism(8)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
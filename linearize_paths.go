@@ -0,0 +1,345 @@
+package linearize
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Paths renders every change in the mask as a path of proto field names, in the format of
+// Path.Format and ParsePath. Repeated elements are addressed by index (Repeated[1]) and map entries
+// by key (Map["key"]). Masks address map entries by position, so keys are looked up in objs,
+// usually the before and after objects returned by Diff. A map whose changed entries cannot all
+// be found there is rendered as the whole field. Paths are returned in field order.
+func Paths(mask *UpdateMask, md protoreflect.MessageDescriptor, objs ...LinearizedObject) []string {
+	var paths []Path
+	appendMaskPaths(&paths, nil, mask, md, objectValues(objs))
+
+	formatted := make([]string, len(paths))
+	for i, path := range paths {
+		formatted[i] = path.Format(md)
+	}
+	return formatted
+}
+
+// ParsePaths builds an UpdateMask from paths in the format produced by Paths and accepted by
+// ParsePath. Map keys are converted to positions by looking them up in objs, usually the object
+// the mask is merged into and the diff. Paths carry no operation, so every path is marked as an
+// UPDATE, even when the mask it was rendered from added or removed the value; Merge then copies
+// whatever the diff holds at that path. Callers that need ADD or REMOVE must set the Op themselves.
+func ParsePaths(paths []string, md protoreflect.MessageDescriptor, objs ...LinearizedObject) (*UpdateMask, error) {
+	mask := &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+	for _, s := range paths {
+		path, err := ParsePath(s, md)
+		if err != nil {
+			return nil, err
+		}
+		if err := addMaskPath(mask, path, objectValues(objs)); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", s, err)
+		}
+	}
+	return mask, nil
+}
+
+// objectValues returns the objects as values that can be walked alongside a mask.
+func objectValues(objs []LinearizedObject) []any {
+	values := make([]any, len(objs))
+	for i, obj := range objs {
+		values[i] = obj
+	}
+	return values
+}
+
+// appendMaskPaths walks the mask in field order and appends a path for each leaf. values are the
+// values the mask applies to, used to look up the keys of map entries.
+func appendMaskPaths(paths *[]Path, prefix Path, mask *UpdateMask, md protoreflect.MessageDescriptor, values []any) {
+	if mask == nil {
+		return
+	}
+
+	for _, pos := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[pos]
+		path := prefix.Field(pos)
+		fieldValues := maskChildValues(values, pos)
+
+		var fd protoreflect.FieldDescriptor
+		if md != nil {
+			fd = md.Fields().ByNumber(protoreflect.FieldNumber(pos))
+		}
+		if !hasNestedMask(maskValue) || fd == nil {
+			*paths = append(*paths, path)
+			continue
+		}
+
+		switch {
+		case fd.IsList():
+			for _, index := range sortedMaskKeys(maskValue.Masks) {
+				elem := maskValue.Masks.Values[index]
+				elemPath := path.Index(index)
+				if hasNestedMask(elem) && fd.Message() != nil {
+					appendMaskPaths(paths, elemPath, elem.Masks, fd.Message(), maskChildValues(fieldValues, index))
+				} else {
+					*paths = append(*paths, elemPath)
+				}
+			}
+
+		case fd.IsMap():
+			positions := sortedMaskKeys(maskValue.Masks)
+			keys := make([]any, len(positions))
+			resolved := true
+			for i, position := range positions {
+				keys[i], resolved = mapEntryKey(fieldValues, position)
+				if !resolved {
+					break
+				}
+			}
+			if !resolved {
+				*paths = append(*paths, path)
+				continue
+			}
+
+			for i, position := range positions {
+				entry := maskValue.Masks.Values[position]
+				entryPath := path.Key(keys[i])
+				if hasNestedMask(entry) && fd.MapValue().Message() != nil {
+					appendMaskPaths(paths, entryPath, entry.Masks, fd.MapValue().Message(), maskChildValues(fieldValues, position))
+				} else {
+					*paths = append(*paths, entryPath)
+				}
+			}
+
+		case fd.Message() != nil:
+			appendMaskPaths(paths, path, maskValue.Masks, fd.Message(), fieldValues)
+
+		default:
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// maskChildValues returns the value stored under a mask key in each of values. Map entries are
+// looked up by position and yield the entry value.
+func maskChildValues(values []any, pos int32) []any {
+	children := make([]any, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case LinearizedObject:
+			children = append(children, v[pos])
+		case LinearizedSlice:
+			children = append(children, v[pos])
+		case LinearizedMap:
+			children = append(children, v[pos][1])
+		}
+	}
+	return children
+}
+
+// mapEntryKey returns the key of the map entry at position in the first of values that has it.
+func mapEntryKey(values []any, position int32) (any, bool) {
+	for _, value := range values {
+		if m, ok := value.(LinearizedMap); ok {
+			if entry, exists := m[position]; exists && entry[0] != nil {
+				return entry[0], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// addMaskPath adds the masks needed to reach path, looking up map keys in values.
+func addMaskPath(mask *UpdateMask, path Path, values []any) error {
+	for i, elem := range path {
+		var pos int32
+		switch elem.Kind {
+		case FieldElement:
+			pos = elem.Field
+		case IndexElement:
+			pos = elem.Index
+		case KeyElement:
+			pos = -1
+			for _, value := range values {
+				if m, ok := value.(LinearizedMap); ok {
+					if pos = mapPosition(m, elem.Key); pos >= 0 {
+						break
+					}
+				}
+			}
+			if pos < 0 {
+				return fmt.Errorf("%w: map key %s", ErrPathNotFound, formatMapKey(elem.Key))
+			}
+		}
+
+		children := make([]any, 0, len(values))
+		for _, value := range values {
+			if child, exists, _ := child(value, elem); exists {
+				children = append(children, child)
+			}
+		}
+		values = children
+
+		maskValue := childMask(mask, pos)
+		if i < len(path)-1 {
+			mask = maskValue.ensureMasks()
+		}
+	}
+	return nil
+}
+
+// childMask returns the mask value for pos, creating an UPDATE if it does not exist.
+func childMask(mask *UpdateMask, pos int32) *UpdateMaskValue {
+	if mask.Values == nil {
+		mask.Values = make(map[int32]*UpdateMaskValue)
+	}
+	maskValue, exists := mask.Values[pos]
+	if !exists {
+		maskValue = &UpdateMaskValue{Op: UpdateMaskOperation_UPDATE}
+		mask.Values[pos] = maskValue
+	}
+	return maskValue
+}
+
+// ensureMasks returns the nested mask, creating it if needed.
+func (x *UpdateMaskValue) ensureMasks() *UpdateMask {
+	if x.Masks == nil {
+		x.Masks = &UpdateMask{Values: make(map[int32]*UpdateMaskValue)}
+	}
+	return x.Masks
+}
+
+// hasNestedMask reports whether the mask value addresses anything below itself.
+func hasNestedMask(maskValue *UpdateMaskValue) bool {
+	return maskValue.Masks != nil && len(maskValue.Masks.Values) > 0
+}
+
+// sortedMaskKeys returns the keys of the mask in ascending order.
+func sortedMaskKeys(mask *UpdateMask) []int32 {
+	keys := make([]int32, 0, len(mask.GetValues()))
+	for key := range mask.GetValues() {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// lookupField resolves a field by proto name, JSON name or field number.
+func lookupField(md protoreflect.MessageDescriptor, name string) (protoreflect.FieldDescriptor, error) {
+	fields := md.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd, nil
+	}
+	if fd := fields.ByJSONName(name); fd != nil {
+		return fd, nil
+	}
+	if number, err := strconv.Atoi(name); err == nil {
+		if fd := fields.ByNumber(protoreflect.FieldNumber(number)); fd != nil {
+			return fd, nil
+		}
+	}
+	return nil, fmt.Errorf("field %s not found in message %s", name, md.FullName())
+}
+
+type pathTokenKind int
+
+const (
+	pathTokenField pathTokenKind = iota // field name or number
+	pathTokenIndex                      // [1]
+	pathTokenKey                        // ["key"]
+)
+
+// pathToken is a single segment of a textual path.
+type pathToken struct {
+	kind  pathTokenKind
+	name  string // field name for pathTokenField, key for pathTokenKey
+	index int64  // index for pathTokenIndex
+}
+
+func (t pathToken) String() string {
+	switch t.kind {
+	case pathTokenIndex:
+		return fmt.Sprintf("[%d]", t.index)
+	case pathTokenKey:
+		return fmt.Sprintf("[%s]", strconv.Quote(t.name))
+	}
+	return t.name
+}
+
+// parsePathTokens splits a path such as nested.repeated[1].map["key"] into tokens.
+func parsePathTokens(path string) ([]pathToken, error) {
+	var tokens []pathToken
+	rest := path
+	for rest != "" {
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("invalid path %q: expected field name", path)
+		}
+		tokens = append(tokens, pathToken{kind: pathTokenField, name: rest[:end]})
+		rest = rest[end:]
+
+		for strings.HasPrefix(rest, "[") {
+			closing := closingBracket(rest)
+			if closing < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated [", path)
+			}
+			token, err := parseBracket(rest[1:closing])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			tokens = append(tokens, token)
+			rest = rest[closing+1:]
+		}
+
+		if rest == "" {
+			break
+		}
+		if rest[0] != '.' || len(rest) == 1 {
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, rest)
+		}
+		rest = rest[1:]
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid path %q: empty path", path)
+	}
+	return tokens, nil
+}
+
+// closingBracket returns the index of the ] that closes the [ at the start of s,
+// skipping over quoted keys.
+func closingBracket(s string) int {
+	inQuote := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case inQuote && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseBracket parses the contents of a [...] segment.
+func parseBracket(s string) (pathToken, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		key, err := strconv.Unquote(s)
+		if err != nil {
+			return pathToken{}, fmt.Errorf("invalid map key %s", s)
+		}
+		return pathToken{kind: pathTokenKey, name: key}, nil
+	}
+
+	index, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return pathToken{kind: pathTokenKey, name: s}, nil
+	}
+	return pathToken{kind: pathTokenIndex, index: index}, nil
+}
@@ -0,0 +1,141 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaths(t *testing.T) {
+	md := (&mocks.Complex{}).ProtoReflect().Descriptor()
+
+	t.Run("should render mask as field paths", func(t *testing.T) {
+		// Arrange
+		msg1 := mocks.CreateComplexMessage()
+		linearized1, err := Linearize(msg1)
+		require.NoError(t, err)
+
+		msg2 := mocks.CreateComplexMessage()
+		msg2.Field1 = "changed_field1"
+		msg2.Nested.Repeated = []string{"value1", "changed"}
+		msg2.Repeated[1].Field2 = 7
+		msg2.Map["key2"].Field1 = "changed"
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+
+		before, after, mask, err := Diff(linearized1, linearized2)
+		require.NoError(t, err)
+
+		// Act
+		paths := Paths(mask, md, before, after)
+
+		// Assert
+		assert.Equal(t, []string{
+			"Field1",
+			"Nested.Repeated[1]",
+			"Repeated[1].Field2",
+			`Map["key2"].Field1`,
+		}, paths)
+	})
+
+	t.Run("should render maps as a whole without objects to look up keys", func(t *testing.T) {
+		// Arrange
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{
+			5: {Op: UpdateMaskOperation_UPDATE, Masks: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+				1: {Op: UpdateMaskOperation_REMOVE},
+			}}},
+		}}
+
+		// Act
+		paths := Paths(mask, md)
+
+		// Assert
+		assert.Equal(t, []string{"Map"}, paths)
+	})
+
+	t.Run("should parse paths into mask", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+		paths := []string{"Field1", "Nested.Repeated[1]", "Repeated[1].Field2", `Map["key2"].Field1`}
+
+		// Act
+		mask, err := ParsePaths(paths, md, linearized)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, paths, Paths(mask, md, linearized))
+		assert.Equal(t, UpdateMaskOperation_UPDATE, mask.Values[5].Masks.Values[1].Masks.Values[1].Op)
+	})
+
+	t.Run("should mark every parsed path as an update", func(t *testing.T) {
+		// Arrange
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{
+			1: {Op: UpdateMaskOperation_REMOVE},
+			2: {Op: UpdateMaskOperation_ADD},
+			3: {Op: UpdateMaskOperation_UPDATE, Masks: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+				1: {Op: UpdateMaskOperation_REMOVE},
+			}}},
+		}}
+
+		// Act
+		parsed, err := ParsePaths(Paths(mask, md), md)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, UpdateMaskOperation_UPDATE, parsed.Values[1].Op)
+		assert.Equal(t, UpdateMaskOperation_UPDATE, parsed.Values[2].Op)
+		assert.Equal(t, UpdateMaskOperation_UPDATE, parsed.Values[3].Op)
+		assert.Equal(t, UpdateMaskOperation_UPDATE, parsed.Values[3].Masks.Values[1].Op)
+	})
+
+	t.Run("should parse paths produced by Path.Format", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+		path := Path{}.Field(5).Key("key1").Field(2)
+
+		// Act
+		mask, err := ParsePaths([]string{path.Format(md), path.String()}, md, linearized)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{path.Format(md)}, Paths(mask, md, linearized))
+	})
+
+	t.Run("should reject unknown fields", func(t *testing.T) {
+		// Act
+		_, err := ParsePaths([]string{"Nested.Missing"}, md)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject map keys missing from the objects", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		// Act
+		_, err = ParsePaths([]string{`Map["missing"].Field1`}, md, linearized)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrPathNotFound)
+	})
+
+	t.Run("should reject indices out of range", func(t *testing.T) {
+		for _, path := range []string{"Repeated[-1]", "Repeated[2147483648]", "Repeated[99999999999999999999]"} {
+			_, err := ParsePaths([]string{path}, md)
+			assert.ErrorIs(t, err, ErrInvalidPath, path)
+		}
+	})
+
+	t.Run("should reject malformed paths", func(t *testing.T) {
+		for _, path := range []string{"", "Field1.", ".Field1", "Repeated[1", "Repeated[x]]", "Repeated.Field1", "Map[#1]"} {
+			_, err := ParsePaths([]string{path}, md)
+			assert.Error(t, err, path)
+		}
+	})
+}
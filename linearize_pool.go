@@ -0,0 +1,20 @@
+package linearize
+
+// Objects, slices and maps are handed to callers, who may keep and share their nested values the way
+// Diff results share them with their inputs, so they are never pooled. They are pre-sized instead,
+// and only buffers that never leave a call, such as those map entries are sorted in, are pooled.
+
+// newObject returns an empty object sized for size fields.
+func newObject(size int) LinearizedObject {
+	return make(LinearizedObject, size)
+}
+
+// newSlice returns an empty slice sized for size elements.
+func newSlice(size int) LinearizedSlice {
+	return make(LinearizedSlice, size)
+}
+
+// newMap returns an empty map sized for size entries.
+func newMap(size int) LinearizedMap {
+	return make(LinearizedMap, size)
+}
@@ -0,0 +1,33 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinearizeOwnership(t *testing.T) {
+	t.Run("should keep values shared by diff results when more messages are linearized", func(t *testing.T) {
+		// Arrange
+		previous, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		changed := mocks.CreateSuperComplexMessage()
+		changed.Field1 = "changed"
+		latest, err := Linearize(changed)
+		require.NoError(t, err)
+		_, after, _, err := Diff(previous, latest)
+		require.NoError(t, err)
+		expected := after.Clone()
+
+		// Act
+		for i := 0; i < 100; i++ {
+			_, err := Linearize(mocks.CreateComplexMessage())
+			require.NoError(t, err)
+		}
+
+		// Assert
+		assert.Equal(t, expected, after)
+	})
+}
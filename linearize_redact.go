@@ -0,0 +1,211 @@
+package linearize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ErrRedacted is returned by Merge when a diff carries redacted placeholders instead of values.
+var ErrRedacted = errors.New("diff contains redacted values")
+
+// Redacted replaces the value of a sensitive field. Hash is a salted hash of the original value, so
+// equal values redact to the same placeholder and changes stay visible without revealing the value.
+type Redacted struct {
+	Hash [32]byte
+}
+
+func (r Redacted) String() string {
+	return fmt.Sprintf("[REDACTED %x]", r.Hash[:8])
+}
+
+// IsSensitive reports whether a field is marked with the debug_redact or (linearize.field).redact option.
+func IsSensitive(fd protoreflect.FieldDescriptor) bool {
+	if fd == nil {
+		return false
+	}
+	if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDebugRedact() {
+		return true
+	}
+	return fieldOptions(fd).GetRedact()
+}
+
+// Redact returns a copy of the object in which the values of sensitive fields are replaced by
+// Redacted placeholders. The hash is an HMAC-SHA256 of the value's Hash keyed with the salt. When
+// the salt is empty a random salt generated once per process is used, so hashes only match within
+// the process and low-entropy values cannot be recovered from them by brute force.
+func Redact(obj LinearizedObject, md protoreflect.MessageDescriptor, salt []byte) LinearizedObject {
+	if obj == nil {
+		return nil
+	}
+	return redactObject(obj, md, salt)
+}
+
+// WithRedaction makes Diff return before and after objects with sensitive fields of the message
+// described by md redacted, using the salt like Redact. The mask still reports every change. Diffs
+// produced this way cannot be merged when they change a sensitive field.
+func WithRedaction(md protoreflect.MessageDescriptor, salt []byte) DiffOption {
+	return diffOptionFunc(func(o *diffOptions) {
+		o.redactMD = md
+		o.salt = salt
+	})
+}
+
+func redactObject(obj LinearizedObject, md protoreflect.MessageDescriptor, salt []byte) LinearizedObject {
+	result := make(LinearizedObject, len(obj))
+	for key, value := range obj {
+		var fd protoreflect.FieldDescriptor
+		if md != nil {
+			fd = md.Fields().ByNumber(protoreflect.FieldNumber(key))
+		}
+		result[key] = redactField(value, fd, salt)
+	}
+	return result
+}
+
+// redactField redacts a field value, or the sensitive fields of the messages it contains.
+func redactField(value any, fd protoreflect.FieldDescriptor, salt []byte) any {
+	if value == nil || fd == nil {
+		return value
+	}
+	if IsSensitive(fd) {
+		return redactValue(value, salt)
+	}
+
+	switch v := value.(type) {
+	case LinearizedObject:
+		return redactObject(v, fd.Message(), salt)
+	case LinearizedSlice:
+		result := make(LinearizedSlice, len(v))
+		for index, elem := range v {
+			if obj, ok := elem.(LinearizedObject); ok {
+				elem = redactObject(obj, fd.Message(), salt)
+			}
+			result[index] = elem
+		}
+		return result
+	case LinearizedMap:
+		result := make(LinearizedMap, len(v))
+		for position, entry := range v {
+			if obj, ok := entry[1].(LinearizedObject); ok && fd.IsMap() {
+				entry = [2]any{entry[0], redactObject(obj, fd.MapValue().Message(), salt)}
+			}
+			result[position] = entry
+		}
+		return result
+	}
+	return value
+}
+
+// processSalt returns the salt used when none is given, generating it on first use.
+var processSalt = sync.OnceValue(func() []byte {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		panic(fmt.Sprintf("linearize: cannot generate redaction salt: %v", err))
+	}
+	return salt
+})
+
+// redactValue returns the placeholder for a value. Values that are already redacted are kept.
+func redactValue(value any, salt []byte) any {
+	if redacted, ok := value.(Redacted); ok {
+		return redacted
+	}
+	if len(salt) == 0 {
+		salt = processSalt()
+	}
+	hash := hashValue(value)
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(hash[:])
+
+	var redacted Redacted
+	mac.Sum(redacted.Hash[:0])
+	return redacted
+}
+
+// findRedacted returns a *PathError for the first redacted placeholder in a value the mask
+// applies, or nil. Placeholders the mask leaves alone, such as unchanged sensitive fields, are never
+// merged.
+func findRedacted(mask *UpdateMask, diff LinearizedObject) error {
+	if !containsRedacted(diff) {
+		return nil
+	}
+	return findMaskedRedacted(nil, mask, diff)
+}
+
+// containsRedacted reports whether a value holds a Redacted placeholder anywhere. It is cheaper than
+// finding the path of one, which most diffs never need.
+func containsRedacted(value any) bool {
+	switch v := value.(type) {
+	case Redacted:
+		return true
+	case LinearizedObject:
+		for _, elem := range v {
+			if containsRedacted(elem) {
+				return true
+			}
+		}
+	case LinearizedSlice:
+		for _, elem := range v {
+			if containsRedacted(elem) {
+				return true
+			}
+		}
+	case LinearizedMap:
+		for _, entry := range v {
+			if containsRedacted(entry[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func findMaskedRedacted(path Path, mask *UpdateMask, value any) error {
+	if mask.GetListKey() != 0 {
+		// Keyed list masks are not indexed like the list, so every element is checked
+		return walkValue(path, value, rejectRedacted)
+	}
+	for _, pos := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[pos]
+		if maskValue.Op == UpdateMaskOperation_REMOVE {
+			continue
+		}
+
+		var elem any
+		var elemPath Path
+		switch container := value.(type) {
+		case LinearizedObject:
+			elem, elemPath = container[pos], path.Field(pos)
+		case LinearizedSlice:
+			elem, elemPath = container[pos], path.Index(pos)
+		case LinearizedMap:
+			entry := container[pos]
+			elem, elemPath = entry[1], path.Key(entry[0])
+		}
+
+		var err error
+		if _, redacted := elem.(Redacted); redacted || maskValue.Masks == nil {
+			err = walkValue(elemPath, elem, rejectRedacted)
+		} else {
+			err = findMaskedRedacted(elemPath, maskValue.Masks, elem)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rejectRedacted(path Path, value any) error {
+	if _, ok := value.(Redacted); ok {
+		return &PathError{Op: "merge", Path: path, Err: ErrRedacted}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package linearize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	md := (&mocks.Annotated{}).ProtoReflect().Descriptor()
+	salt := []byte("salt")
+
+	diffAnnotated := func(t *testing.T, opts ...DiffOption) (LinearizedObject, LinearizedObject, LinearizedObject, *UpdateMask) {
+		previous, err := Linearize(&mocks.Annotated{Name: "before", Secret: "s3cret", Password: "hunter2"})
+		require.NoError(t, err)
+		latest, err := Linearize(&mocks.Annotated{Name: "after", Secret: "s3cret", Password: "hunter3"})
+		require.NoError(t, err)
+		before, after, mask, err := Diff(previous, latest, opts...)
+		require.NoError(t, err)
+		return previous, before, after, mask
+	}
+
+	t.Run("should replace sensitive values with salted hashes", func(t *testing.T) {
+		// Act
+		_, before, after, mask := diffAnnotated(t, WithRedaction(md, salt))
+
+		// Assert
+		assert.Equal(t, []string{"Name", "Password"}, Paths(mask, md))
+		assert.Equal(t, "after", after[1])
+		assert.IsType(t, Redacted{}, before[7])
+		assert.IsType(t, Redacted{}, after[7])
+		assert.NotEqual(t, before[7], after[7])
+
+		// Equal values redact to the same placeholder only with the same salt
+		redacted := Redact(LinearizedObject{6: "s3cret"}, md, salt)
+		assert.Equal(t, redacted, Redact(LinearizedObject{6: "s3cret"}, md, salt))
+		assert.NotEqual(t, redacted, Redact(LinearizedObject{6: "s3cret"}, md, []byte("other")))
+	})
+
+	t.Run("should hash the same value differently under different salts", func(t *testing.T) {
+		// Arrange
+		obj := LinearizedObject{6: "s3cret"}
+		var unsalted Redacted
+		mac := hmac.New(sha256.New, nil)
+		hash := hashValue("s3cret")
+		mac.Write(hash[:])
+		mac.Sum(unsalted.Hash[:0])
+
+		// Act
+		first := Redact(obj, md, []byte("first"))
+		second := Redact(obj, md, []byte("second"))
+		defaulted := Redact(obj, md, nil)
+
+		// Assert
+		assert.NotEqual(t, first[6], second[6])
+		assert.NotEqual(t, unsalted, defaulted[6])
+		assert.Equal(t, defaulted, Redact(obj, md, []byte{}))
+	})
+
+	t.Run("should render hashes keyed with the renderer's salt", func(t *testing.T) {
+		// Arrange
+		_, before, after, mask := diffAnnotated(t)
+		render := func(salt []byte) string {
+			var sb strings.Builder
+			require.NoError(t, Renderer{Salt: salt}.Render(&sb, md, before, after, mask))
+			return sb.String()
+		}
+
+		// Act
+		first, second := render([]byte("first")), render([]byte("second"))
+
+		// Assert
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, RenderDiff(md, before, after, mask), render(nil))
+	})
+
+	t.Run("should redact rendered values", func(t *testing.T) {
+		// Arrange
+		_, before, after, mask := diffAnnotated(t)
+
+		// Act
+		rendered := RenderDiff(md, before, after, mask)
+
+		// Assert
+		assert.NotContains(t, rendered, "hunter")
+		assert.Contains(t, rendered, "Password: UPDATE\n-     [REDACTED ")
+		assert.Contains(t, rendered, `+     "after"`)
+	})
+
+	t.Run("should refuse to merge redacted values", func(t *testing.T) {
+		// Arrange
+		current, _, after, mask := diffAnnotated(t, WithRedaction(md, salt))
+
+		// Act
+		err := Merge(mask, current, after)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRedacted)
+		var pathErr *PathError
+		require.ErrorAs(t, err, &pathErr)
+		assert.Equal(t, "7", pathErr.Path.String())
+		assert.Equal(t, "before", current[1])
+	})
+
+	t.Run("should encode redacted values", func(t *testing.T) {
+		// Arrange
+		_, before, after, mask := diffAnnotated(t, WithRedaction(md, salt))
+
+		// Act
+		data, err := (&Patch{Mask: mask, Before: before, After: after}).MarshalBinary()
+		require.NoError(t, err)
+		var decoded Patch
+		err = decoded.UnmarshalBinary(data)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, after[7], decoded.After[7])
+	})
+}
@@ -0,0 +1,269 @@
+package linearize
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultMaxValueLength is the number of characters after which rendered values are truncated.
+const DefaultMaxValueLength = 80
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Renderer formats the result of Diff as a unified-diff-style tree. Each changed field is
+// shown with its proto name and ADD, UPDATE or REMOVE marker, followed by the before value
+// on a line starting with - and the after value on a line starting with +.
+type Renderer struct {
+	Color          bool   // Color wraps markers and values in ANSI color codes
+	MaxValueLength int    // Values are truncated after this many characters; 0 uses DefaultMaxValueLength, negative disables truncation
+	Salt           []byte // Salt keys the hashes shown for sensitive fields; when empty a random per-process salt is used, see Redact
+}
+
+// RenderDiff renders a Diff result without colors using the default value length. Sensitive
+// fields are hashed with the per-process salt, see Redact.
+func RenderDiff(md protoreflect.MessageDescriptor, before, after LinearizedObject, mask *UpdateMask) string {
+	var sb strings.Builder
+	_ = Renderer{}.Render(&sb, md, before, after, mask)
+	return sb.String()
+}
+
+// Render writes the Diff result to w. The descriptor is used for field names and may be nil,
+// in which case field numbers are shown instead. Values of sensitive fields are always redacted.
+func (r Renderer) Render(w io.Writer, md protoreflect.MessageDescriptor, before, after LinearizedObject, mask *UpdateMask) error {
+	rs := &renderState{Renderer: r, w: w}
+	if md != nil {
+		before, after = Redact(before, md, r.Salt), Redact(after, md, r.Salt)
+	}
+
+	name := "message"
+	if md != nil {
+		name = string(md.FullName())
+	}
+	rs.line(' ', 0, "", name+" {")
+	rs.object(1, md, before, after, mask)
+	rs.line(' ', 0, "", "}")
+	return rs.err
+}
+
+// renderState carries the writer and the first write error through the render.
+type renderState struct {
+	Renderer
+	w   io.Writer
+	err error
+}
+
+// object renders the masked fields of an object.
+func (rs *renderState) object(depth int, md protoreflect.MessageDescriptor, before, after LinearizedObject, mask *UpdateMask) {
+	if mask == nil {
+		return
+	}
+
+	for _, pos := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[pos]
+
+		var fd protoreflect.FieldDescriptor
+		if md != nil {
+			fd = md.Fields().ByNumber(protoreflect.FieldNumber(pos))
+		}
+		name := strconv.Itoa(int(pos))
+		if fd != nil {
+			name = string(fd.Name())
+		}
+
+		prevValue, hadPrev := before[pos]
+		latestValue, hasLatest := after[pos]
+
+		switch {
+		case !hasNestedMask(maskValue) || fd == nil || IsSensitive(fd):
+			rs.leaf(depth, name, maskValue.Op, fd, prevValue, hadPrev, latestValue, hasLatest)
+
+		case fd.IsList():
+			prevSlice, _ := prevValue.(LinearizedSlice)
+			latestSlice, _ := latestValue.(LinearizedSlice)
+			rs.slice(depth, name, fd, prevSlice, latestSlice, maskValue.Masks)
+
+		case fd.IsMap():
+			prevMap, _ := prevValue.(LinearizedMap)
+			latestMap, _ := latestValue.(LinearizedMap)
+			rs.mapEntries(depth, name, fd, prevMap, latestMap, maskValue.Masks)
+
+		case fd.Message() != nil:
+			prevObj, _ := prevValue.(LinearizedObject)
+			latestObj, _ := latestValue.(LinearizedObject)
+			rs.line(' ', depth, ansiCyan, fmt.Sprintf("%s: %s {", name, maskValue.Op))
+			rs.object(depth+1, fd.Message(), prevObj, latestObj, maskValue.Masks)
+			rs.line(' ', depth, "", "}")
+
+		default:
+			rs.leaf(depth, name, maskValue.Op, fd, prevValue, hadPrev, latestValue, hasLatest)
+		}
+	}
+}
+
+// slice renders the masked elements of a repeated field.
+func (rs *renderState) slice(depth int, name string, fd protoreflect.FieldDescriptor, before, after LinearizedSlice, mask *UpdateMask) {
+	for _, index := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[index]
+		elemName := fmt.Sprintf("%s[%d]", name, index)
+		prevElem, hadPrev := before[index]
+		latestElem, hasLatest := after[index]
+
+		prevObj, prevIsObj := prevElem.(LinearizedObject)
+		latestObj, latestIsObj := latestElem.(LinearizedObject)
+		if hasNestedMask(maskValue) && prevIsObj && latestIsObj {
+			rs.line(' ', depth, ansiCyan, fmt.Sprintf("%s: %s {", elemName, maskValue.Op))
+			rs.object(depth+1, fd.Message(), prevObj, latestObj, maskValue.Masks)
+			rs.line(' ', depth, "", "}")
+			continue
+		}
+		rs.leaf(depth, elemName, maskValue.Op, fd, prevElem, hadPrev && prevElem != nil, latestElem, hasLatest && latestElem != nil)
+	}
+}
+
+// mapEntries renders the masked entries of a map field, addressed by key.
+func (rs *renderState) mapEntries(depth int, name string, fd protoreflect.FieldDescriptor, before, after LinearizedMap, mask *UpdateMask) {
+	valueFd := fd.MapValue()
+	for _, position := range sortedMaskKeys(mask) {
+		maskValue := mask.Values[position]
+		prevEntry, hadPrev := before[position]
+		latestEntry, hasLatest := after[position]
+		hadPrev = hadPrev && prevEntry[0] != nil
+		hasLatest = hasLatest && latestEntry[0] != nil
+
+		key := latestEntry[0]
+		if !hasLatest {
+			key = prevEntry[0]
+		}
+		entryName := fmt.Sprintf("%s[%s]", name, formatMapKey(key))
+
+		prevObj, prevIsObj := prevEntry[1].(LinearizedObject)
+		latestObj, latestIsObj := latestEntry[1].(LinearizedObject)
+		if hasNestedMask(maskValue) && prevIsObj && latestIsObj && prevEntry[0] == latestEntry[0] {
+			rs.line(' ', depth, ansiCyan, fmt.Sprintf("%s: %s {", entryName, maskValue.Op))
+			rs.object(depth+1, valueFd.Message(), prevObj, latestObj, maskValue.Masks)
+			rs.line(' ', depth, "", "}")
+			continue
+		}
+		rs.leaf(depth, entryName, maskValue.Op, valueFd, prevEntry[1], hadPrev, latestEntry[1], hasLatest)
+	}
+}
+
+// leaf renders a single change with its before and after values.
+func (rs *renderState) leaf(depth int, name string, op UpdateMaskOperation, fd protoreflect.FieldDescriptor, prevValue any, hadPrev bool, latestValue any, hasLatest bool) {
+	rs.line(' ', depth, ansiCyan, fmt.Sprintf("%s: %s", name, op))
+	if hadPrev && prevValue != nil {
+		rs.line('-', depth+1, ansiRed, rs.truncate(formatValue(prevValue, fd)))
+	}
+	if hasLatest && latestValue != nil && op != UpdateMaskOperation_REMOVE {
+		rs.line('+', depth+1, ansiGreen, rs.truncate(formatValue(latestValue, fd)))
+	}
+}
+
+// line writes a marker column, indentation and text, colored when enabled.
+func (rs *renderState) line(marker byte, depth int, color string, text string) {
+	if rs.err != nil {
+		return
+	}
+
+	content := string(marker) + " " + strings.Repeat("  ", depth) + text
+	if rs.Color && color != "" {
+		content = color + content + ansiReset
+	}
+	_, rs.err = io.WriteString(rs.w, content+"\n")
+}
+
+// truncate shortens a rendered value to the configured length.
+func (rs *renderState) truncate(s string) string {
+	limit := rs.MaxValueLength
+	if limit == 0 {
+		limit = DefaultMaxValueLength
+	}
+
+	runes := []rune(s)
+	if limit < 0 || len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
+
+// formatValue renders a linearized value using field names from the descriptor where available.
+func formatValue(value any, fd protoreflect.FieldDescriptor) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return fmt.Sprintf("%q", v)
+	case protoreflect.EnumNumber:
+		if fd != nil && fd.Enum() != nil {
+			if ev := fd.Enum().Values().ByNumber(v); ev != nil {
+				return string(ev.Name())
+			}
+		}
+		return strconv.Itoa(int(v))
+
+	case LinearizedObject:
+		var md protoreflect.MessageDescriptor
+		if fd != nil {
+			md = fd.Message()
+		}
+		keys := make([]int32, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			var field protoreflect.FieldDescriptor
+			if md != nil {
+				field = md.Fields().ByNumber(protoreflect.FieldNumber(key))
+			}
+			name := strconv.Itoa(int(key))
+			if field != nil {
+				name = string(field.Name())
+			}
+			parts = append(parts, name+": "+formatValue(v[key], field))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+
+	case LinearizedSlice:
+		parts := make([]string, len(v))
+		for i := range parts {
+			parts[i] = formatValue(v[int32(i)], fd)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+
+	case LinearizedMap:
+		var valueFd protoreflect.FieldDescriptor
+		if fd != nil && fd.IsMap() {
+			valueFd = fd.MapValue()
+		}
+		parts := make([]string, len(v))
+		for i := range parts {
+			entry := v[int32(i)]
+			parts[i] = formatMapKey(entry[0]) + ": " + formatValue(entry[1], valueFd)
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+// formatMapKey renders a map key the way it is written in a path.
+func formatMapKey(key any) string {
+	if s, ok := key.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(key)
+}
@@ -0,0 +1,94 @@
+package linearize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	md := (&mocks.Complex{}).ProtoReflect().Descriptor()
+
+	diffComplex := func(t *testing.T, change func(msg *mocks.Complex)) (LinearizedObject, LinearizedObject, *UpdateMask) {
+		linearized1, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+
+		msg2 := mocks.CreateComplexMessage()
+		change(msg2)
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+
+		before, after, mask, err := Diff(linearized1, linearized2)
+		require.NoError(t, err)
+		return before, after, mask
+	}
+
+	t.Run("should render diff as unified tree", func(t *testing.T) {
+		// Arrange
+		before, after, mask := diffComplex(t, func(msg *mocks.Complex) {
+			msg.Field1 = "changed_field1"
+			msg.Nested = nil
+			msg.Repeated[1].Field2 = 7
+			msg.Map["key2"].Field1 = "changed"
+		})
+
+		// Act
+		rendered := RenderDiff(md, before, after, mask)
+
+		// Assert
+		assert.Equal(t, strings.Join([]string{
+			"  mocks.Complex {",
+			"    Field1: UPDATE",
+			`-     "complex_field1"`,
+			`+     "changed_field1"`,
+			"    Nested: REMOVE",
+			`-     {Field1: "test1", Field2: 42, Repeated: ["value1", "value2"]}`,
+			"    Repeated[1]: UPDATE {",
+			"      Field2: UPDATE",
+			"-       42",
+			"+       7",
+			"    }",
+			`    Map["key2"]: UPDATE {`,
+			"      Field1: UPDATE",
+			`-       "test1"`,
+			`+       "changed"`,
+			"    }",
+			"  }",
+			"",
+		}, "\n"), rendered)
+	})
+
+	t.Run("should truncate long values", func(t *testing.T) {
+		// Arrange
+		before, after, mask := diffComplex(t, func(msg *mocks.Complex) {
+			msg.Field1 = strings.Repeat("x", 100)
+		})
+		var sb strings.Builder
+
+		// Act
+		err := Renderer{MaxValueLength: 10}.Render(&sb, md, before, after, mask)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, sb.String(), `+     "xxxxxxxxx…`)
+	})
+
+	t.Run("should color output when enabled", func(t *testing.T) {
+		// Arrange
+		before, after, mask := diffComplex(t, func(msg *mocks.Complex) {
+			msg.Field2 = 1
+		})
+		var sb strings.Builder
+
+		// Act
+		err := Renderer{Color: true}.Render(&sb, md, before, after, mask)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, sb.String(), ansiRed+"-     100"+ansiReset)
+		assert.Contains(t, sb.String(), ansiGreen+"+     1"+ansiReset)
+	})
+}
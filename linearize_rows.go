@@ -0,0 +1,386 @@
+package linearize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ValueType tags the value of a Row.
+type ValueType int
+
+const (
+	TypeNil ValueType = iota
+	TypeObject
+	TypeSlice
+	TypeMap
+	TypeBool
+	TypeInt32
+	TypeInt64
+	TypeUint32
+	TypeUint64
+	TypeFloat32
+	TypeFloat64
+	TypeString
+	TypeBytes
+	TypeEnum
+	TypeRedacted
+)
+
+var valueTypeNames = []string{
+	TypeNil:      "nil",
+	TypeObject:   "object",
+	TypeSlice:    "slice",
+	TypeMap:      "map",
+	TypeBool:     "bool",
+	TypeInt32:    "int32",
+	TypeInt64:    "int64",
+	TypeUint32:   "uint32",
+	TypeUint64:   "uint64",
+	TypeFloat32:  "float32",
+	TypeFloat64:  "float64",
+	TypeString:   "string",
+	TypeBytes:    "bytes",
+	TypeEnum:     "enum",
+	TypeRedacted: "redacted",
+}
+
+func (t ValueType) String() string {
+	if t >= 0 && int(t) < len(valueTypeNames) {
+		return valueTypeNames[t]
+	}
+	return fmt.Sprintf("ValueType(%d)", int(t))
+}
+
+// ParseValueType returns the type named s, as returned by String.
+func ParseValueType(s string) (ValueType, error) {
+	for t, name := range valueTypeNames {
+		if name == s {
+			return ValueType(t), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown value type %q", s)
+}
+
+// ErrInvalidRow is returned by Unflatten for rows that cannot be placed in an object.
+var ErrInvalidRow = errors.New("invalid row")
+
+// Row is a leaf of an object: a scalar, or an object, slice or map without values. Value is nil for
+// the empty containers.
+type Row struct {
+	Path  Path
+	Type  ValueType
+	Value any
+}
+
+// Flatten returns a row for every leaf of the object, in field, index and map key order.
+// Objects, slices and maps are not given rows of their own unless they are empty.
+func Flatten(obj LinearizedObject) ([]Row, error) {
+	var rows []Row
+	err := Emit(obj, NewRowEmitter(func(path Path, value any) error {
+		row, err := newRow(path, value)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	}))
+	return rows, err
+}
+
+// flattenAt returns the rows of a value stored at path.
+func flattenAt(path Path, value any) ([]Row, error) {
+	switch v := value.(type) {
+	case LinearizedObject, LinearizedSlice, LinearizedMap:
+		if reflect.ValueOf(v).Len() > 0 {
+			var rows []Row
+			err := emitValue(0, v, NewRowEmitter(func(child Path, value any) error {
+				row, err := newRow(append(path[:len(path):len(path)], child...), value)
+				if err != nil {
+					return err
+				}
+				rows = append(rows, row)
+				return nil
+			}))
+			return rows, err
+		}
+	}
+	row, err := newRow(path, value)
+	if err != nil {
+		return nil, err
+	}
+	return []Row{row}, nil
+}
+
+// newRow tags a leaf with the type of its value.
+func newRow(path Path, value any) (Row, error) {
+	row := Row{Path: path, Value: value}
+	switch value.(type) {
+	case nil:
+		row.Type = TypeNil
+	case LinearizedObject:
+		row.Type, row.Value = TypeObject, nil
+	case LinearizedSlice:
+		row.Type, row.Value = TypeSlice, nil
+	case LinearizedMap:
+		row.Type, row.Value = TypeMap, nil
+	case bool:
+		row.Type = TypeBool
+	case int32:
+		row.Type = TypeInt32
+	case int64:
+		row.Type = TypeInt64
+	case uint32:
+		row.Type = TypeUint32
+	case uint64:
+		row.Type = TypeUint64
+	case float32:
+		row.Type = TypeFloat32
+	case float64:
+		row.Type = TypeFloat64
+	case string:
+		row.Type = TypeString
+	case []byte:
+		row.Type = TypeBytes
+	case protoreflect.EnumNumber:
+		row.Type = TypeEnum
+	case Redacted:
+		row.Type = TypeRedacted
+	default:
+		return Row{}, fmt.Errorf("%s: cannot flatten value of type %T", path, value)
+	}
+	return row, nil
+}
+
+// Unflatten rebuilds the object described by rows. Rows may come in any order. Values are converted
+// to the Go type of their Type, so rows read back from a database, where integers are int64 and bytes
+// may be strings, can be passed as they are. Map entries are ordered by key like Linearize orders them
+// with order, so objects linearized WithMapKeyOrder(MapKeysNatural) round-trip with MapKeysNatural.
+func Unflatten(rows []Row, order MapKeyOrder) (LinearizedObject, error) {
+	obj := make(LinearizedObject)
+	for _, row := range rows {
+		if err := unflattenRow(obj, row); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check slices and order map entries once every row is in place
+	err := obj.Walk(func(path Path, value any) error {
+		switch v := value.(type) {
+		case LinearizedSlice:
+			for index := range v {
+				if index < 0 || index >= int32(len(v)) {
+					return fmt.Errorf("%w: %s: missing elements before index %d", ErrInvalidRow, path, index)
+				}
+			}
+		case LinearizedMap:
+			sortMapEntries(v, order)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// lessValue orders two keys of a LinearizedMap like less orders the same keys of a message.
+func (order MapKeyOrder) lessValue(a, b any) bool {
+	if order == MapKeysNatural {
+		switch x := a.(type) {
+		case int32:
+			if y, ok := b.(int32); ok {
+				return x < y
+			}
+		case int64:
+			if y, ok := b.(int64); ok {
+				return x < y
+			}
+		case uint32:
+			if y, ok := b.(uint32); ok {
+				return x < y
+			}
+		case uint64:
+			if y, ok := b.(uint64); ok {
+				return x < y
+			}
+		case bool:
+			if y, ok := b.(bool); ok {
+				return !x && y
+			}
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// unflattenRow stores the value of a row, creating the containers along its path.
+func unflattenRow(obj LinearizedObject, row Row) error {
+	if len(row.Path) == 0 {
+		return fmt.Errorf("%w: empty path", ErrInvalidRow)
+	}
+	value, err := row.Type.convert(row.Value)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidRow, row.Path, err)
+	}
+
+	var current any = obj
+	for i, elem := range row.Path {
+		last := i == len(row.Path)-1
+		next := value
+		if !last {
+			next = containerFor(row.Path[i+1])
+		}
+		// Rows of empty containers never replace a container filled by other rows
+		if current, err = unflattenChild(current, elem, next, last && !isContainer(next)); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidRow, row.Path[:i+1], err)
+		}
+	}
+	return nil
+}
+
+// unflattenChild returns the value addressed by elem inside container, storing value there when
+// nothing is stored yet or when replace is set.
+func unflattenChild(container any, elem PathElement, value any, replace bool) (any, error) {
+	switch c := container.(type) {
+	case LinearizedObject:
+		if elem.Kind == FieldElement {
+			if existing, exists := c[elem.Field]; exists && !replace {
+				return existing, nil
+			}
+			c[elem.Field] = value
+			return value, nil
+		}
+	case LinearizedSlice:
+		if elem.Kind == IndexElement {
+			if existing, exists := c[elem.Index]; exists && !replace {
+				return existing, nil
+			}
+			c[elem.Index] = value
+			return value, nil
+		}
+	case LinearizedMap:
+		if elem.Kind == KeyElement {
+			// Rows of an entry usually follow each other, so try the last entry before searching
+			position := int32(len(c)) - 1
+			if position < 0 || !mapKeysEqual(c[position][0], elem.Key) {
+				position = mapPosition(c, elem.Key)
+			}
+			if position < 0 {
+				c[int32(len(c))] = [2]any{elem.Key, value}
+				return value, nil
+			}
+			if replace {
+				c[position] = [2]any{c[position][0], value}
+				return value, nil
+			}
+			return c[position][1], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: cannot apply %s to %T", ErrPathType, Path{elem}, container)
+}
+
+// isContainer reports whether a value is an object, slice or map.
+func isContainer(value any) bool {
+	switch value.(type) {
+	case LinearizedObject, LinearizedSlice, LinearizedMap:
+		return true
+	}
+	return false
+}
+
+// convert returns value as the Go type used for t in a LinearizedObject.
+func (t ValueType) convert(value any) (any, error) {
+	switch t {
+	case TypeNil:
+		return nil, nil
+	case TypeObject:
+		return make(LinearizedObject), nil
+	case TypeSlice:
+		return make(LinearizedSlice), nil
+	case TypeMap:
+		return make(LinearizedMap), nil
+	case TypeBool:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+		if v, ok := integer(value); ok {
+			return v != 0, nil
+		}
+	case TypeInt32, TypeInt64, TypeUint32, TypeUint64, TypeEnum:
+		v, ok := integer(value)
+		if !ok {
+			break
+		}
+		switch t {
+		case TypeInt32:
+			return int32(v), nil
+		case TypeInt64:
+			return v, nil
+		case TypeUint32:
+			return uint32(v), nil
+		case TypeUint64:
+			return uint64(v), nil
+		}
+		return protoreflect.EnumNumber(v), nil
+	case TypeFloat32, TypeFloat64:
+		var v float64
+		switch f := value.(type) {
+		case float32:
+			v = float64(f)
+		case float64:
+			v = f
+		default:
+			i, ok := integer(value)
+			if !ok {
+				return nil, fmt.Errorf("expected %s but got %T", t, value)
+			}
+			v = float64(i)
+		}
+		if t == TypeFloat32 {
+			return float32(v), nil
+		}
+		return v, nil
+	case TypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		}
+	case TypeBytes:
+		switch v := value.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+	case TypeRedacted:
+		switch v := value.(type) {
+		case Redacted:
+			return v, nil
+		case []byte:
+			var redacted Redacted
+			if len(v) == len(redacted.Hash) {
+				copy(redacted.Hash[:], v)
+				return redacted, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown value type %s", t)
+	}
+	return nil, fmt.Errorf("expected %s but got %T", t, value)
+}
+
+// integer returns the value of any integer type as an int64, keeping the bits of large unsigned values.
+func integer(value any) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,174 @@
+package linearize
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestFlatten(t *testing.T) {
+	t.Run("should return a row for every leaf", func(t *testing.T) {
+		// Arrange
+		obj := LinearizedObject{
+			1: "value",
+			2: LinearizedSlice{0: int32(1)},
+			3: LinearizedMap{0: {"key", LinearizedObject{1: protoreflect.EnumNumber(2)}}},
+			4: LinearizedObject{},
+		}
+
+		// Act
+		rows, err := Flatten(obj)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []Row{
+			{Path: Path{}.Field(1), Type: TypeString, Value: "value"},
+			{Path: Path{}.Field(2).Index(0), Type: TypeInt32, Value: int32(1)},
+			{Path: Path{}.Field(3).Key("key").Field(1), Type: TypeEnum, Value: protoreflect.EnumNumber(2)},
+			{Path: Path{}.Field(4), Type: TypeObject},
+		}, rows)
+	})
+
+	t.Run("should reject values it cannot tag", func(t *testing.T) {
+		// Act
+		_, err := Flatten(LinearizedObject{1: 1})
+
+		// Assert
+		assert.ErrorContains(t, err, "cannot flatten value of type int")
+	})
+
+	for name, create := range map[string]func() LinearizedObject{
+		"super complex": func() LinearizedObject {
+			obj, _ := Linearize(mocks.CreateSuperComplexMessage())
+			return obj
+		},
+		"large": func() LinearizedObject {
+			obj, _ := Linearize(largeMessage())
+			return obj
+		},
+		"empty containers": func() LinearizedObject {
+			return LinearizedObject{1: LinearizedObject{}, 2: LinearizedSlice{}, 3: LinearizedMap{}, 4: nil}
+		},
+	} {
+		t.Run("should unflatten "+name+" rows in any order", func(t *testing.T) {
+			// Arrange
+			obj := create()
+			rows, err := Flatten(obj)
+			require.NoError(t, err)
+			slices.Reverse(rows)
+
+			// Act
+			actual, err := Unflatten(rows, MapKeysLexical)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, obj, actual)
+		})
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	t.Run("should convert values read back from a database", func(t *testing.T) {
+		// Arrange
+		rows := []Row{
+			{Path: Path{}.Field(1), Type: TypeInt32, Value: int64(-1)},
+			{Path: Path{}.Field(2), Type: TypeUint64, Value: int64(-1)},
+			{Path: Path{}.Field(3), Type: TypeBool, Value: int64(1)},
+			{Path: Path{}.Field(4), Type: TypeBytes, Value: "bytes"},
+			{Path: Path{}.Field(5), Type: TypeFloat32, Value: float64(1.5)},
+			{Path: Path{}.Field(6), Type: TypeEnum, Value: int64(2)},
+			{Path: Path{}.Field(7).Key(int64(10)), Type: TypeString, Value: []byte("ten")},
+			{Path: Path{}.Field(7).Key(int64(2)), Type: TypeString, Value: "two"},
+		}
+
+		// Act
+		obj, err := Unflatten(rows, MapKeysLexical)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, LinearizedObject{
+			1: int32(-1),
+			2: uint64(1<<64 - 1),
+			3: true,
+			4: []byte("bytes"),
+			5: float32(1.5),
+			6: protoreflect.EnumNumber(2),
+			7: LinearizedMap{0: {int64(10), "ten"}, 1: {int64(2), "two"}},
+		}, obj)
+	})
+
+	t.Run("should keep children when an empty container row follows them", func(t *testing.T) {
+		// Arrange
+		rows := []Row{
+			{Path: Path{}.Field(1).Field(2), Type: TypeString, Value: "value"},
+			{Path: Path{}.Field(3).Index(0), Type: TypeInt32, Value: int32(1)},
+			{Path: Path{}.Field(4).Key("key"), Type: TypeBool, Value: true},
+			{Path: Path{}.Field(1), Type: TypeObject},
+			{Path: Path{}.Field(3), Type: TypeSlice},
+			{Path: Path{}.Field(4), Type: TypeMap},
+		}
+
+		// Act
+		obj, err := Unflatten(rows, MapKeysLexical)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, LinearizedObject{
+			1: LinearizedObject{2: "value"},
+			3: LinearizedSlice{0: int32(1)},
+			4: LinearizedMap{0: {"key", true}},
+		}, obj)
+	})
+
+	t.Run("should order map entries like the given map key order", func(t *testing.T) {
+		// Arrange
+		msg := &mocks.SuperComplex{Map: map[int32]*mocks.Complex{
+			2:  {Field1: "two"},
+			10: {Field1: "ten"},
+		}}
+		expected, err := Linearize(msg, WithMapKeyOrder(MapKeysNatural))
+		require.NoError(t, err)
+		rows, err := Flatten(expected)
+		require.NoError(t, err)
+
+		// Act
+		actual, err := Unflatten(rows, MapKeysNatural)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should reject invalid rows", func(t *testing.T) {
+		rows := map[string][]Row{
+			"empty path":        {{Type: TypeString, Value: "value"}},
+			"wrong value type":  {{Path: Path{}.Field(1), Type: TypeInt32, Value: "value"}},
+			"missing element":   {{Path: Path{}.Field(1).Index(1), Type: TypeInt32, Value: int32(1)}},
+			"conflicting paths": {{Path: Path{}.Field(1).Index(0), Type: TypeInt32, Value: int32(1)}, {Path: Path{}.Field(1).Field(2), Type: TypeInt32, Value: int32(1)}},
+		}
+		for name, rows := range rows {
+			t.Run("should reject "+name, func(t *testing.T) {
+				// Act
+				_, err := Unflatten(rows, MapKeysLexical)
+
+				// Assert
+				assert.ErrorIs(t, err, ErrInvalidRow)
+			})
+		}
+	})
+
+	t.Run("should parse value type names", func(t *testing.T) {
+		for valueType := TypeNil; valueType <= TypeRedacted; valueType++ {
+			// Act
+			parsed, err := ParseValueType(valueType.String())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, valueType, parsed)
+		}
+	})
+}
@@ -0,0 +1,444 @@
+package linearize
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Dialect selects the SQL syntax of the statements generated for an SQLTable.
+type Dialect int
+
+const (
+	// SQLite uses ? placeholders.
+	SQLite Dialect = iota
+	// Postgres uses $1, $2, ... placeholders.
+	Postgres
+)
+
+// Statement is a parameterized SQL statement.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// SQLTable describes a path/value table holding the rows of flattened documents. Every row stores the
+// Path.String() of a leaf, its ValueType.String() and its value as text, so the table can be queried
+// without a mapper per message. Bytes and redacted values are base64 encoded.
+type SQLTable struct {
+	Dialect     Dialect
+	Name        string
+	KeyColumn   string // column identifying the document, or empty when the table holds a single document
+	PathColumn  string
+	TypeColumn  string
+	ValueColumn string
+}
+
+// NewSQLTable returns a table with the columns doc, path, type and value.
+func NewSQLTable(dialect Dialect, name string) SQLTable {
+	return SQLTable{
+		Dialect:     dialect,
+		Name:        name,
+		KeyColumn:   "doc",
+		PathColumn:  "path",
+		TypeColumn:  "type",
+		ValueColumn: "value",
+	}
+}
+
+// CreateStatement returns a CREATE TABLE statement for the table, keyed by document and path.
+func (t SQLTable) CreateStatement() string {
+	var columns, key []string
+	if t.KeyColumn != "" {
+		columns = append(columns, quoteIdentifier(t.KeyColumn)+" TEXT NOT NULL")
+		key = append(key, quoteIdentifier(t.KeyColumn))
+	}
+	columns = append(columns,
+		quoteIdentifier(t.PathColumn)+" TEXT NOT NULL",
+		quoteIdentifier(t.TypeColumn)+" TEXT NOT NULL",
+		quoteIdentifier(t.ValueColumn)+" TEXT",
+	)
+	key = append(key, quoteIdentifier(t.PathColumn))
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s, PRIMARY KEY (%s))",
+		quoteIdentifier(t.Name), strings.Join(columns, ", "), strings.Join(key, ", "))
+}
+
+// InsertStatements returns the statements storing every row of a document.
+func (t SQLTable) InsertStatements(key any, obj LinearizedObject) ([]Statement, error) {
+	rows, err := Flatten(obj)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]Statement, 0, len(rows))
+	for _, row := range rows {
+		statements = append(statements, t.insert(key, row))
+	}
+	return statements, nil
+}
+
+// PatchStatements returns the statements applying a Diff result to the rows of a document. Changed
+// scalars become UPDATE statements, added values INSERT statements and removed values DELETE statements
+// covering everything below them. Values replaced by a different shape are deleted and inserted again.
+// The before object is only needed for patches changing maps, whose entries are stored by key.
+func (t SQLTable) PatchStatements(key any, patch Patch) ([]Statement, error) {
+	if patch.Mask == nil {
+		return nil, nil
+	}
+	g := sqlGenerator{table: t, key: key}
+	if err := g.object(nil, patch.Mask, patch.Before, patch.After); err != nil {
+		return nil, err
+	}
+	return g.statements, nil
+}
+
+// sqlGenerator collects the statements of a patch.
+type sqlGenerator struct {
+	table      SQLTable
+	key        any
+	statements []Statement
+}
+
+// object generates the statements for the fields of an object changed by the mask.
+func (g *sqlGenerator) object(path Path, mask *UpdateMask, before, after LinearizedObject) error {
+	for _, pos := range sortedMaskKeys(mask) {
+		var beforeValue any
+		if before != nil {
+			beforeValue = before[pos]
+		}
+		if err := g.value(path.Field(pos), mask.Values[pos], beforeValue, after[pos]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value generates the statements for a value changed by a mask value.
+func (g *sqlGenerator) value(path Path, maskValue *UpdateMaskValue, before, after any) error {
+	if maskValue.Op == UpdateMaskOperation_REMOVE || after == nil {
+		g.delete(path)
+		return nil
+	}
+	if !hasNestedMask(maskValue) {
+		return g.replace(path, maskValue.Op, after)
+	}
+
+	var err error
+	switch v := after.(type) {
+	case LinearizedObject:
+		beforeObj, _ := before.(LinearizedObject)
+		err = g.object(path, maskValue.Masks, beforeObj, v)
+	case LinearizedSlice:
+		beforeSlice, _ := before.(LinearizedSlice)
+		for _, index := range sortedMaskKeys(maskValue.Masks) {
+			var beforeElem any
+			if beforeSlice != nil {
+				beforeElem = beforeSlice[index]
+			}
+			if err = g.value(path.Index(index), maskValue.Masks.Values[index], beforeElem, v[index]); err != nil {
+				break
+			}
+		}
+	case LinearizedMap:
+		beforeMap, _ := before.(LinearizedMap)
+		err = g.mapEntries(path, maskValue.Masks, beforeMap, v)
+	default:
+		return fmt.Errorf("%s: nested mask for %T", path, after)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Empty containers are stored as a row of their own, which must follow their children
+	if before == nil {
+		return nil
+	}
+	beforeLen, afterLen := counts(maskValue.Masks, before, after)
+	switch {
+	case beforeLen == 0 && afterLen > 0:
+		g.deleteRow(path)
+	case beforeLen > 0 && afterLen == 0:
+		// newRow tags containers by type without storing their values
+		row, err := newRow(path, after)
+		if err != nil {
+			return err
+		}
+		g.statements = append(g.statements, g.table.insert(g.key, row))
+	}
+	return nil
+}
+
+// counts returns the number of values a container holds before and after the mask is applied to it.
+// Diff results hold nil for unchanged slice elements and removed values, so values are counted by
+// position: every position of before existed unless the mask adds it.
+func counts(mask *UpdateMask, before, after any) (int, int) {
+	var beforeLen, afterLen int
+	for _, pos := range containerPositions(before) {
+		maskValue, masked := mask.Values[pos]
+		if !masked {
+			afterLen++
+		}
+		if !masked || maskValue.Op != UpdateMaskOperation_ADD {
+			beforeLen++
+		}
+	}
+	for pos, maskValue := range mask.Values {
+		if maskValue.Op != UpdateMaskOperation_REMOVE && containerValue(after, pos) != nil {
+			afterLen++
+		}
+	}
+	return beforeLen, afterLen
+}
+
+// containerPositions returns the positions of an object, slice or map.
+func containerPositions(container any) []int32 {
+	switch c := container.(type) {
+	case LinearizedObject:
+		return slices.Collect(maps.Keys(c))
+	case LinearizedSlice:
+		return slices.Collect(maps.Keys(c))
+	case LinearizedMap:
+		return slices.Collect(maps.Keys(c))
+	}
+	return nil
+}
+
+// containerValue returns the value at a position of an object, slice or map.
+func containerValue(container any, pos int32) any {
+	switch c := container.(type) {
+	case LinearizedObject:
+		return c[pos]
+	case LinearizedSlice:
+		return c[pos]
+	case LinearizedMap:
+		if entry := c[pos]; entry[0] != nil {
+			return entry[1]
+		}
+	}
+	return nil
+}
+
+// mapEntries generates the statements for a map. Entries are compared by position, so an entry may
+// hold a different key than before. The rows of replaced keys are deleted before any entry is written,
+// since a key may move to another position.
+func (g *sqlGenerator) mapEntries(path Path, mask *UpdateMask, before, after LinearizedMap) error {
+	positions := sortedMaskKeys(mask)
+	rewritten := make(map[int32]bool)
+	for _, pos := range positions {
+		maskValue := mask.Values[pos]
+		if maskValue.Op == UpdateMaskOperation_ADD {
+			continue
+		}
+		beforeEntry, ok := before[pos]
+		if !ok {
+			return fmt.Errorf("%s: patch has no before value for map entry %d", path, pos)
+		}
+		if maskValue.Op == UpdateMaskOperation_REMOVE || after[pos][0] == nil || after[pos][0] != beforeEntry[0] {
+			g.delete(path.Key(beforeEntry[0]))
+			rewritten[pos] = true
+		}
+	}
+
+	for _, pos := range positions {
+		maskValue := mask.Values[pos]
+		entry := after[pos]
+		switch {
+		case maskValue.Op == UpdateMaskOperation_REMOVE || entry[0] == nil:
+			continue
+		case maskValue.Op == UpdateMaskOperation_ADD || rewritten[pos]:
+			if err := g.insert(path.Key(entry[0]), entry[1]); err != nil {
+				return err
+			}
+		default:
+			if err := g.value(path.Key(entry[0]), maskValue, before[pos][1], entry[1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replace stores a value that is added or replaced as a whole.
+func (g *sqlGenerator) replace(path Path, op UpdateMaskOperation, value any) error {
+	switch value.(type) {
+	case LinearizedObject, LinearizedSlice, LinearizedMap:
+		if op != UpdateMaskOperation_ADD {
+			g.delete(path)
+		}
+		return g.insert(path, value)
+	}
+	if op == UpdateMaskOperation_ADD {
+		return g.insert(path, value)
+	}
+
+	row, err := newRow(path, value)
+	if err != nil {
+		return err
+	}
+	t := g.table
+	args := []any{row.Type.String(), encodeSQLValue(row)}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s WHERE ", quoteIdentifier(t.Name),
+		quoteIdentifier(t.TypeColumn), t.placeholder(1), quoteIdentifier(t.ValueColumn), t.placeholder(2))
+	where, args := t.where(args, g.key, path.String())
+	g.statements = append(g.statements, Statement{SQL: query + where, Args: args})
+	return nil
+}
+
+// deleteRow removes the row of a path, leaving the rows below it.
+func (g *sqlGenerator) deleteRow(path Path) {
+	t := g.table
+	where, args := t.where(nil, g.key, path.String())
+	g.statements = append(g.statements, Statement{SQL: fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdentifier(t.Name), where), Args: args})
+}
+
+// insert stores every row of a value.
+func (g *sqlGenerator) insert(path Path, value any) error {
+	rows, err := flattenAt(path, value)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		g.statements = append(g.statements, g.table.insert(g.key, row))
+	}
+	return nil
+}
+
+// delete removes the rows of a value and of everything below it.
+func (g *sqlGenerator) delete(path Path) {
+	t := g.table
+	prefix := path.String()
+	query := fmt.Sprintf("DELETE FROM %s WHERE ", quoteIdentifier(t.Name))
+	var args []any
+	if t.KeyColumn != "" {
+		args = append(args, g.key)
+		query += fmt.Sprintf("%s = %s AND ", quoteIdentifier(t.KeyColumn), t.placeholder(len(args)))
+	}
+	args = append(args, prefix, utf8.RuneCountInString(prefix)+1, prefix+".", prefix+"[")
+	column := quoteIdentifier(t.PathColumn)
+	query += fmt.Sprintf("(%s = %s OR substr(%s, 1, %s) IN (%s, %s))", column, t.placeholder(len(args)-3),
+		column, t.placeholder(len(args)-2), t.placeholder(len(args)-1), t.placeholder(len(args)))
+	g.statements = append(g.statements, Statement{SQL: query, Args: args})
+}
+
+// insert returns the statement storing a row.
+func (t SQLTable) insert(key any, row Row) Statement {
+	var columns []string
+	var args []any
+	if t.KeyColumn != "" {
+		columns = append(columns, quoteIdentifier(t.KeyColumn))
+		args = append(args, key)
+	}
+	columns = append(columns, quoteIdentifier(t.PathColumn), quoteIdentifier(t.TypeColumn), quoteIdentifier(t.ValueColumn))
+	args = append(args, row.Path.String(), row.Type.String(), encodeSQLValue(row))
+
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = t.placeholder(i + 1)
+	}
+	return Statement{
+		SQL: fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(t.Name),
+			strings.Join(columns, ", "), strings.Join(placeholders, ", ")),
+		Args: args,
+	}
+}
+
+// where returns the condition selecting the row of a path in a document, appending its arguments to args.
+func (t SQLTable) where(args []any, key any, path string) (string, []any) {
+	var conditions []string
+	if t.KeyColumn != "" {
+		args = append(args, key)
+		conditions = append(conditions, fmt.Sprintf("%s = %s", quoteIdentifier(t.KeyColumn), t.placeholder(len(args))))
+	}
+	args = append(args, path)
+	conditions = append(conditions, fmt.Sprintf("%s = %s", quoteIdentifier(t.PathColumn), t.placeholder(len(args))))
+	return strings.Join(conditions, " AND "), args
+}
+
+// placeholder returns the placeholder of the nth argument.
+func (t SQLTable) placeholder(n int) string {
+	if t.Dialect == Postgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// quoteIdentifier quotes a table or column name.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// encodeSQLValue returns the text stored for the value of a row, or nil for empty containers.
+func encodeSQLValue(row Row) any {
+	switch v := row.Value.(type) {
+	case nil:
+		return nil
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case protoreflect.EnumNumber:
+		return strconv.FormatInt(int64(v), 10)
+	case Redacted:
+		return base64.StdEncoding.EncodeToString(v.Hash[:])
+	}
+	return fmt.Sprint(row.Value)
+}
+
+// ParseSQLRow decodes a row read back from an SQLTable. The path is resolved against the descriptor
+// of the document so map keys get their declared types. The rows of a document can be passed to Unflatten.
+func ParseSQLRow(md protoreflect.MessageDescriptor, path, valueType string, value sql.NullString) (Row, error) {
+	parsedPath, err := ParsePath(path, md)
+	if err != nil {
+		return Row{}, err
+	}
+	t, err := ParseValueType(valueType)
+	if err != nil {
+		return Row{}, err
+	}
+	row := Row{Path: parsedPath, Type: t}
+	if row.Value, err = parseSQLValue(t, value); err != nil {
+		return Row{}, fmt.Errorf("%w: %s: %v", ErrInvalidRow, path, err)
+	}
+	return row, nil
+}
+
+// parseSQLValue decodes the text stored for a value of type t.
+func parseSQLValue(t ValueType, value sql.NullString) (any, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	text := value.String
+	switch t {
+	case TypeBool:
+		return strconv.ParseBool(text)
+	case TypeInt32, TypeInt64, TypeEnum:
+		return strconv.ParseInt(text, 10, 64)
+	case TypeUint32, TypeUint64:
+		return strconv.ParseUint(text, 10, 64)
+	case TypeFloat32, TypeFloat64:
+		return strconv.ParseFloat(text, 64)
+	case TypeBytes, TypeRedacted:
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return text, nil
+}
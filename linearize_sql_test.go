@@ -0,0 +1,108 @@
+package linearize
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The statements are run against SQLite by the sqltest module, which keeps the driver out of this
+// module. Postgres statements are only compared as text.
+func TestSQLTable(t *testing.T) {
+	t.Run("should round trip every value type", func(t *testing.T) {
+		// Arrange
+		table := NewSQLTable(SQLite, "documents")
+		obj := LinearizedObject{
+			1:  true,
+			2:  int32(-1),
+			3:  int64(-2),
+			4:  uint32(3),
+			5:  uint64(1<<64 - 1),
+			6:  float32(1.5),
+			7:  -2.25,
+			8:  "text",
+			9:  []byte{0, 1, 2},
+			10: Redacted{Hash: [32]byte{1}},
+		}
+		statements, err := table.InsertStatements("doc", obj)
+		require.NoError(t, err)
+
+		expected, err := Flatten(obj)
+		require.NoError(t, err)
+		paths := make(map[string]Path)
+		for _, row := range expected {
+			paths[row.Path.String()] = row.Path
+		}
+
+		// Act
+		var flattened []Row
+		for _, statement := range statements {
+			row := Row{Path: paths[statement.Args[1].(string)]}
+			row.Type, err = ParseValueType(statement.Args[2].(string))
+			require.NoError(t, err)
+			value, _ := statement.Args[3].(string)
+			row.Value, err = parseSQLValue(row.Type, sql.NullString{String: value, Valid: statement.Args[3] != nil})
+			require.NoError(t, err)
+			flattened = append(flattened, row)
+		}
+		actual, err := Unflatten(flattened, MapKeysLexical)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, obj, actual)
+	})
+
+	t.Run("should generate postgres statements", func(t *testing.T) {
+		// Arrange
+		table := NewSQLTable(Postgres, "documents")
+		patch := Patch{
+			Mask: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+				1: {Op: UpdateMaskOperation_UPDATE},
+				2: {Op: UpdateMaskOperation_REMOVE},
+				3: {Op: UpdateMaskOperation_ADD},
+			}},
+			After: LinearizedObject{1: "changed", 2: nil, 3: LinearizedSlice{0: int32(7)}},
+		}
+
+		// Act
+		statements, err := table.PatchStatements("doc", patch)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []Statement{
+			{
+				SQL:  `UPDATE "documents" SET "type" = $1, "value" = $2 WHERE "doc" = $3 AND "path" = $4`,
+				Args: []any{"string", "changed", "doc", "1"},
+			},
+			{
+				SQL:  `DELETE FROM "documents" WHERE "doc" = $1 AND ("path" = $2 OR substr("path", 1, $3) IN ($4, $5))`,
+				Args: []any{"doc", "2", 2, "2.", "2["},
+			},
+			{
+				SQL:  `INSERT INTO "documents" ("doc", "path", "type", "value") VALUES ($1, $2, $3, $4)`,
+				Args: []any{"doc", "3[0]", "int32", "7"},
+			},
+		}, statements)
+	})
+
+	t.Run("should require the before value to change maps", func(t *testing.T) {
+		// Arrange
+		table := NewSQLTable(SQLite, "documents")
+		patch := Patch{
+			Mask: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+				4: {Op: UpdateMaskOperation_UPDATE, Masks: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+					0: {Op: UpdateMaskOperation_REMOVE},
+				}}},
+			}},
+			After: LinearizedObject{4: LinearizedMap{0: {}}},
+		}
+
+		// Act
+		_, err := table.PatchStatements("doc", patch)
+
+		// Assert
+		assert.ErrorContains(t, err, "no before value")
+	})
+}
@@ -0,0 +1,58 @@
+package linearize
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// UnlinearizeAs rebuilds a new message of type T from a LinearizedObject. T must be a generated
+// message pointer type such as *mypb.User.
+func UnlinearizeAs[T proto.Message](obj LinearizedObject) (T, error) {
+	var zero T
+	msg := zero.ProtoReflect().New().Interface().(T)
+	if err := Unlinearize(obj, msg); err != nil {
+		return zero, err
+	}
+	return msg, nil
+}
+
+// DiffMessages diffs two messages of the same type. It walks both messages together and returns the
+// same patch as Diff(Linearize(previous), Linearize(latest), WithDescriptor(md), opts...), where md is
+// the descriptor of the messages, linearizing only the values that appear in it. The descriptor makes
+// Diff honor (linearize.field) options without further options. T may be proto.Message itself, so
+// messages whose type is only known at run time can be diffed too; messages of different types are
+// rejected. Messages with a generated Diff method are compared by it.
+func DiffMessages[T proto.Message](previous, latest T, opts ...DiffOption) (Patch, error) {
+	if generated, ok := any(previous).(messageDiffer[T]); ok {
+		return generated.Diff(latest, opts...)
+	}
+
+	md := latest.ProtoReflect().Descriptor()
+	if previous.ProtoReflect().Descriptor().FullName() != md.FullName() {
+		return Patch{}, fmt.Errorf("cannot diff %s with %s", previous.ProtoReflect().Descriptor().FullName(), md.FullName())
+	}
+	options := newDiffOptions(append([]DiffOption{WithDescriptor(md)}, opts...))
+	before, after, mask, err := options.finish(options.differ().diffMessages(previous.ProtoReflect(), latest.ProtoReflect()))
+	if err != nil {
+		return Patch{}, err
+	}
+	return Patch{Mask: mask, Before: before, After: after}, nil
+}
+
+// ApplyPatch returns a copy of the message with the patch merged into it by MergeInto, or by the
+// generated Merge method of the message. The message itself is not modified.
+func ApplyPatch[T proto.Message](msg T, patch Patch, opts ...MergeOption) (T, error) {
+	var zero T
+	patched := proto.Clone(msg).(T)
+	var err error
+	if generated, ok := any(patched).(messageMerger); ok {
+		err = generated.Merge(patch.Mask, patch.After, opts...)
+	} else {
+		err = MergeInto(patched, patch.Mask, patch.After, opts...)
+	}
+	if err != nil {
+		return zero, err
+	}
+	return patched, nil
+}
@@ -0,0 +1,57 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTyped(t *testing.T) {
+	t.Run("should unlinearize into a new message", func(t *testing.T) {
+		// Arrange
+		linearized, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+
+		// Act
+		msg, err := UnlinearizeAs[*mocks.SuperComplex](linearized)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(mocks.CreateSuperComplexMessage(), msg))
+	})
+
+	t.Run("should diff and apply typed messages", func(t *testing.T) {
+		// Arrange
+		previous := mocks.CreateSuperComplexMessage()
+		latest := mocks.CreateSuperComplexMessage()
+		latest.Field1 = "changed"
+		latest.Nested.Map["key3"] = mocks.CreateSimpleMessage()
+		latest.Repeated = append(latest.Repeated, &mocks.Complex{Field1: "appended"})
+
+		// Act
+		patch, err := DiffMessages(previous, latest)
+		require.NoError(t, err)
+		patched, err := ApplyPatch(previous, patch)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(latest, patched))
+		assert.Equal(t, "supercomplex_field1", previous.Field1)
+	})
+
+	t.Run("should honor field options", func(t *testing.T) {
+		// Arrange
+		previous := &mocks.Annotated{Name: "name", UpdatedAt: 1}
+		latest := &mocks.Annotated{Name: "name", UpdatedAt: 2}
+
+		// Act
+		patch, err := DiffMessages(previous, latest)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, patch.Mask)
+	})
+}
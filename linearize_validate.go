@@ -0,0 +1,158 @@
+package linearize
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrSchemaViolation is matched by errors reporting that an object does not match its message descriptor.
+var ErrSchemaViolation = errors.New("object does not match schema")
+
+// Violation describes a single value that does not match the schema.
+type Violation struct {
+	Path    Path
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationError lists every schema violation found in an object.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrSchemaViolation, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+// Validate checks that every value in the object matches the message descriptor: field numbers must
+// exist, scalars must have the Go type Linearize produces for their kind, and slices and maps must be
+// densely numbered. It returns a *ValidationError listing all violations in path order, or nil.
+func Validate(obj LinearizedObject, md protoreflect.MessageDescriptor) error {
+	var violations []Violation
+	validateObject(&violations, nil, obj, md)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateObject checks the fields of an object against a message descriptor.
+func validateObject(violations *[]Violation, path Path, obj LinearizedObject, md protoreflect.MessageDescriptor) {
+	if expanded, ok := obj[anyValue].(LinearizedObject); ok && md.FullName() == anyFullName {
+		validateAny(violations, path, obj, expanded)
+		return
+	}
+	for _, key := range sortedKeys(obj) {
+		fieldPath := path.Field(key)
+		fd := md.Fields().ByNumber(protoreflect.FieldNumber(key))
+		if fd == nil {
+			addViolation(violations, fieldPath, "unknown field %d in %s", key, md.FullName())
+			continue
+		}
+		validateField(violations, fieldPath, obj[key], fd)
+	}
+}
+
+// validateAny checks a google.protobuf.Any expanded by WithResolver against the message it holds.
+func validateAny(violations *[]Violation, path Path, obj, expanded LinearizedObject) {
+	packed, err := resolveAny(obj)
+	if err != nil {
+		addViolation(violations, path.Field(anyTypeURL), "%v", err)
+		return
+	}
+	validateObject(violations, path.Field(anyValue), expanded, packed.ProtoReflect().Descriptor())
+}
+
+// validateField checks the value of a single field, including the elements of repeated and map fields.
+func validateField(violations *[]Violation, path Path, value any, fd protoreflect.FieldDescriptor) {
+	// Unlinearize treats nil as an unset field
+	if value == nil {
+		return
+	}
+
+	switch {
+	case fd.IsMap():
+		m, ok := value.(LinearizedMap)
+		if !ok {
+			addViolation(violations, path, "expected map for field %s but got %T", fd.Name(), value)
+			return
+		}
+		validatePositions(violations, path, sortedKeys(m))
+
+		keys := make(map[string]bool, len(m))
+		for _, position := range sortedKeys(m) {
+			entry := m[position]
+			entryPath := path.Key(entry[0])
+			if _, err := scalarValue(entry[0], fd.MapKey()); err != nil {
+				addViolation(violations, entryPath, "invalid map key: %v", err)
+				continue
+			}
+			key := string(canonicalBytes(entry[0]))
+			if keys[key] {
+				addViolation(violations, entryPath, "duplicate map key")
+				continue
+			}
+			keys[key] = true
+			validateElement(violations, entryPath, entry[1], fd.MapValue())
+		}
+
+	case fd.IsList():
+		s, ok := value.(LinearizedSlice)
+		if !ok {
+			addViolation(violations, path, "expected slice for field %s but got %T", fd.Name(), value)
+			return
+		}
+		validatePositions(violations, path, sortedKeys(s))
+		for _, index := range sortedKeys(s) {
+			validateElement(violations, path.Index(index), s[index], fd)
+		}
+
+	default:
+		validateElement(violations, path, value, fd)
+	}
+}
+
+// validateElement checks a singular value, list element or map value.
+func validateElement(violations *[]Violation, path Path, value any, fd protoreflect.FieldDescriptor) {
+	if fd.Message() != nil {
+		obj, ok := value.(LinearizedObject)
+		if !ok {
+			addViolation(violations, path, "expected object for message %s but got %T", fd.Message().FullName(), value)
+			return
+		}
+		validateObject(violations, path, obj, fd.Message())
+		return
+	}
+
+	if _, err := scalarValue(value, fd); err != nil {
+		addViolation(violations, path, "%v", err)
+	}
+}
+
+// validatePositions reports slices and maps whose sorted keys are not numbered 0..n-1.
+func validatePositions(violations *[]Violation, path Path, keys []int32) {
+	for i, key := range keys {
+		if key != int32(i) {
+			addViolation(violations, path, "expected contiguous positions but found %d at %d", key, i)
+			return
+		}
+	}
+}
+
+func addViolation(violations *[]Violation, path Path, format string, args ...any) {
+	*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
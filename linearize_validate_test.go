@@ -0,0 +1,118 @@
+package linearize
+
+import (
+	"testing"
+
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	md := (&mocks.SuperComplex{}).ProtoReflect().Descriptor()
+
+	linearizeSuperComplex := func(t *testing.T) LinearizedObject {
+		linearized, err := Linearize(mocks.CreateSuperComplexMessage())
+		require.NoError(t, err)
+		return linearized
+	}
+
+	t.Run("should accept linearized messages", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+
+		// Act
+		err := Validate(linearized, md)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should report every violation with its path", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+		linearized[2] = "not a number"
+		linearized[99] = int32(1)
+		linearized[1] = LinearizedObject{}
+		nested := linearized[3].(LinearizedObject)
+		nested[5].(LinearizedMap)[1][1].(LinearizedObject)[2] = int64(2)
+		repeated := linearized[4].(LinearizedSlice)
+		repeated[2] = repeated[0]
+		delete(repeated, 0)
+
+		// Act
+		err := Validate(linearized, md)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrSchemaViolation)
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+
+		paths := make([]string, len(validationErr.Violations))
+		for i, violation := range validationErr.Violations {
+			paths[i] = violation.Path.Format(md)
+		}
+		assert.Equal(t, []string{"Field1", "Field2", `Nested.Map["key2"].Field2`, "Repeated", "99"}, paths)
+		assert.Contains(t, validationErr.Violations[1].Message, "expected int32 but got string")
+	})
+
+	t.Run("should reject merges that break the schema", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+		original := linearized.Clone()
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{
+			1: {Op: UpdateMaskOperation_UPDATE},
+			2: {Op: UpdateMaskOperation_UPDATE},
+		}}
+		diff := LinearizedObject{1: "changed", 2: "oops"}
+
+		// Act
+		err := Merge(mask, linearized, diff, WithValidation(md))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrSchemaViolation)
+		assert.True(t, original.Equal(linearized))
+	})
+
+	t.Run("should apply valid merges", func(t *testing.T) {
+		// Arrange
+		linearized := linearizeSuperComplex(t)
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{2: {Op: UpdateMaskOperation_UPDATE}}}
+		diff := LinearizedObject{2: int32(7)}
+
+		// Act
+		err := Merge(mask, linearized, diff, WithValidation(md))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(7), linearized[2])
+	})
+}
+
+func TestUnlinearizeInvalid(t *testing.T) {
+	md := (&mocks.SuperComplex{}).ProtoReflect().Descriptor()
+	objects := map[string]struct {
+		obj  LinearizedObject
+		path string
+	}{
+		"object for a scalar":      {LinearizedObject{1: LinearizedObject{}}, "Field1"},
+		"scalar for a message":     {LinearizedObject{3: "not an object"}, "Nested"},
+		"nil element":              {LinearizedObject{4: LinearizedSlice{0: nil}}, "Repeated[0]"},
+		"missing element":          {LinearizedObject{4: LinearizedSlice{1: LinearizedObject{}}}, "Repeated[1]"},
+		"wrong map key type":       {LinearizedObject{5: LinearizedMap{0: {"two", LinearizedObject{}}}}, `Map["two"]`},
+		"wrong nested scalar type": {LinearizedObject{3: LinearizedObject{2: "not a number"}}, "Nested.Field2"},
+	}
+
+	for name, tc := range objects {
+		t.Run("should report "+name+" without panicking", func(t *testing.T) {
+			// Act
+			err := Unlinearize(tc.obj, &mocks.SuperComplex{})
+
+			// Assert
+			assert.ErrorIs(t, err, ErrSchemaViolation)
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.path, validationErr.Violations[0].Path.Format(md))
+		})
+	}
+}
@@ -317,6 +317,49 @@ func TestComplex(t *testing.T) {
 		assert.NotNil(t, mask)
 	})
 
+	t.Run("should diff map values whose key is unchanged", func(t *testing.T) {
+		// Arrange
+		msg1 := mocks.CreateComplexMessage()
+		linearized1, err := Linearize(msg1)
+		require.NoError(t, err)
+
+		msg2 := mocks.CreateComplexMessage()
+		msg2.Map["key1"].Field1 = "changed"
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+
+		// Act
+		_, after, mask, err := Diff(linearized1, linearized2)
+
+		// Assert
+		require.NoError(t, err)
+		require.Contains(t, mask.Values, int32(5))
+		entryMask := mask.Values[5].Masks.Values[0]
+		assert.Equal(t, UpdateMaskOperation_UPDATE, entryMask.Op)
+		assert.Equal(t, UpdateMaskOperation_UPDATE, entryMask.Masks.Values[1].Op)
+		entry := after[5].(LinearizedMap)[0]
+		assert.Equal(t, "key1", entry[0])
+		assert.Equal(t, "changed", entry[1].(LinearizedObject)[1])
+	})
+
+	t.Run("should merge nested fields into a missing nested object", func(t *testing.T) {
+		// Arrange
+		current := LinearizedObject{1: "complex_field1"}
+		mask := &UpdateMask{Values: map[int32]*UpdateMaskValue{
+			3: {Op: UpdateMaskOperation_UPDATE, Masks: &UpdateMask{Values: map[int32]*UpdateMaskValue{
+				1: {Op: UpdateMaskOperation_UPDATE},
+			}}},
+		}}
+		diff := LinearizedObject{3: LinearizedObject{1: "nested"}}
+
+		// Act
+		err := Merge(mask, current, diff)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, LinearizedObject{1: "nested"}, current[3])
+	})
+
 	t.Run("should merge messages using update mask", func(t *testing.T) {
 		// Arrange
 		msg1 := mocks.CreateComplexMessage()
@@ -346,3 +389,83 @@ func TestComplex(t *testing.T) {
 
 	})
 }
+
+func TestOptions(t *testing.T) {
+	t.Run("should limit message depth", func(t *testing.T) {
+		// Arrange
+		msg := mocks.CreateSuperComplexMessage()
+
+		// Act
+		_, shallowErr := Linearize(msg, WithMaxDepth(2))
+		linearized, err := Linearize(msg, WithMaxDepth(3))
+		require.NoError(t, err)
+		changed := linearized.Clone()
+		changed[3].(LinearizedObject)[3].(LinearizedObject)[1] = "changed"
+		_, _, _, diffErr := Diff(linearized, changed, WithMaxDepth(2))
+
+		// Assert
+		assert.ErrorIs(t, shallowErr, ErrDepthExceeded)
+		assert.ErrorIs(t, diffErr, ErrDepthExceeded)
+	})
+
+	t.Run("should order map keys naturally", func(t *testing.T) {
+		// Arrange
+		msg := &mocks.SuperComplex{Map: map[int32]*mocks.Complex{
+			2:  {Field1: "two"},
+			10: {Field1: "ten"},
+		}}
+
+		// Act
+		lexical, err := Linearize(msg)
+		require.NoError(t, err)
+		natural, err := Linearize(msg, WithMapKeyOrder(MapKeysNatural))
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, int32(10), lexical[5].(LinearizedMap)[0][0])
+		assert.Equal(t, int32(2), natural[5].(LinearizedMap)[0][0])
+	})
+
+	t.Run("should replace whole lists", func(t *testing.T) {
+		// Arrange
+		linearized1, err := Linearize(mocks.CreateComplexMessage())
+		require.NoError(t, err)
+		msg2 := mocks.CreateComplexMessage()
+		msg2.Repeated[1].Field1 = "changed"
+		linearized2, err := Linearize(msg2)
+		require.NoError(t, err)
+
+		// Act
+		_, after, mask, err := Diff(linearized1, linearized2, WithListStrategy(ListReplace))
+		require.NoError(t, err)
+		err = Merge(mask, linearized1, after)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, mask.Values[4].Masks)
+		assert.True(t, linearized2.Equal(linearized1))
+	})
+}
+
+func BenchmarkLinearize(b *testing.B) {
+	messages := []struct {
+		name string
+		msg  proto.Message
+	}{
+		{"Simple", mocks.CreateSimpleMessage()},
+		{"Complex", mocks.CreateComplexMessage()},
+		{"SuperComplex", mocks.CreateSuperComplexMessage()},
+		{"Large", largeMessage()},
+	}
+
+	for _, m := range messages {
+		b.Run(m.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Linearize(m.msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 //go:generate go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
 //go:generate go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
+//go:generate go install ../cmd/protoc-gen-linearize
 
 //go:generate protoc --go_out=./ --go_opt=paths=source_relative --proto_path=./ mocks.proto
+//go:generate protoc --go_out=./ --go_opt=paths=source_relative --proto_path=./ --proto_path=../proto annotated.proto
+//go:generate protoc --go_out=./generated --go_opt=paths=source_relative --linearize_out=./generated --linearize_opt=paths=source_relative --proto_path=./ --proto_path=./generated generated.proto
 
 package mocks
@@ -0,0 +1,442 @@
+package generated
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fgrzl/linearize"
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// reflective forces Linearize to use protoreflect, since generated methods are only used without options.
+var reflective = linearize.WithMaxDepth(0)
+
+func createScalars() *Scalars {
+	optional := int32(0)
+	return &Scalars{
+		Double:        math.Copysign(0, -1),
+		Float:         1.5,
+		Int32:         -3,
+		Int64:         -4,
+		Uint32:        5,
+		Uint64:        6,
+		Sint32:        -7,
+		Sint64:        -8,
+		Fixed32:       9,
+		Fixed64:       10,
+		Sfixed32:      -11,
+		Sfixed64:      -12,
+		Bool:          true,
+		String_:       "string",
+		Bytes:         []byte("bytes"),
+		Color:         Color_COLOR_BLUE,
+		OptionalInt32: &optional,
+		Doubles:       []float64{0, 1.25},
+		Colors:        []Color{Color_COLOR_UNSPECIFIED, Color_COLOR_RED},
+		Blobs:         [][]byte{[]byte("a"), {}},
+		ByInt:         map[int64]string{2: "two", 10: "ten", -1: "minus one"},
+		ByBool:        map[bool]*Scalars_Inner{true: {Data: []byte("yes")}, false: {}},
+		ByUint:        map[uint32]Color{1: Color_COLOR_RED, 20: Color_COLOR_BLUE},
+		Choice:        &Scalars_Message{Message: &Scalars_Inner{Data: []byte("inner")}},
+		External:      mocks.CreateSimpleMessage(),
+	}
+}
+
+// createSuperComplex returns the generated copy of mocks.CreateSuperComplexMessage.
+func createSuperComplex(t testing.TB) *SuperComplex {
+	data, err := proto.Marshal(mocks.CreateSuperComplexMessage())
+	require.NoError(t, err)
+	msg := &SuperComplex{}
+	require.NoError(t, proto.Unmarshal(data, msg))
+	return msg
+}
+
+func TestGenerated(t *testing.T) {
+	messages := map[string]func(t testing.TB) proto.Message{
+		"scalars":       func(t testing.TB) proto.Message { return createScalars() },
+		"text choice":   func(t testing.TB) proto.Message { return &Scalars{Choice: &Scalars_Text{Text: ""}} },
+		"empty":         func(t testing.TB) proto.Message { return &Scalars{} },
+		"super complex": func(t testing.TB) proto.Message { return createSuperComplex(t) },
+	}
+
+	for name, create := range messages {
+		t.Run("should linearize "+name+" like protoreflect", func(t *testing.T) {
+			// Arrange
+			msg := create(t)
+			expected, err := linearize.Linearize(msg, reflective)
+			require.NoError(t, err)
+
+			// Act
+			actual, err := linearize.Linearize(msg)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+
+		t.Run("should unlinearize "+name+" like protoreflect", func(t *testing.T) {
+			// Arrange
+			msg := create(t)
+			obj, err := linearize.Linearize(msg, reflective)
+			require.NoError(t, err)
+			expected := dynamicpb.NewMessage(msg.ProtoReflect().Descriptor())
+			require.NoError(t, linearize.Unlinearize(obj, expected))
+
+			// Act
+			actual := msg.ProtoReflect().New().Interface()
+			err = linearize.Unlinearize(obj, actual)
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(expected, actual), "got %v, expected %v", actual, expected)
+			assert.True(t, proto.Equal(msg, actual))
+		})
+	}
+
+	t.Run("should diff and merge like protoreflect", func(t *testing.T) {
+		// Arrange
+		previous := createSuperComplex(t)
+		latest := createSuperComplex(t)
+		latest.Field1 = "changed"
+		latest.Nested.Map["key0"] = &Simple{Field1: "first"}
+		latest.Repeated = append(latest.Repeated, &Complex{Field1: "appended"})
+		previousObj, err := linearize.Linearize(previous, reflective)
+		require.NoError(t, err)
+		latestObj, err := linearize.Linearize(latest, reflective)
+		require.NoError(t, err)
+		before, after, mask, err := linearize.Diff(previousObj, latestObj, linearize.WithDescriptor(previous.ProtoReflect().Descriptor()))
+		require.NoError(t, err)
+
+		// Act
+		patch, err := linearize.DiffMessages(previous, latest)
+		require.NoError(t, err)
+		patched, err := linearize.ApplyPatch(previous, patch)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(mask, patch.Mask))
+		assert.Equal(t, before, patch.Before)
+		assert.Equal(t, after, patch.After)
+		assert.True(t, proto.Equal(latest, patched))
+		assert.Equal(t, "supercomplex_field1", previous.Field1)
+	})
+
+	for name, pair := range pairs() {
+		for optionsName, opts := range diffOptions() {
+			t.Run("should diff "+name+" "+optionsName+" like protoreflect", func(t *testing.T) {
+				// Arrange
+				previous, latest := pair(t)
+				expected, expectedErr := linearize.DiffMessages[proto.Message](previous, latest, opts...)
+
+				// Act
+				actual, err := diffGenerated(previous, latest, opts...)
+
+				// Assert
+				if expectedErr != nil {
+					assert.EqualError(t, err, expectedErr.Error())
+					return
+				}
+				require.NoError(t, err)
+				assert.True(t, proto.Equal(expected.Mask, actual.Mask), "got %v, expected %v", actual.Mask, expected.Mask)
+				assert.Equal(t, expected.Before, actual.Before)
+				assert.Equal(t, expected.After, actual.After)
+			})
+		}
+
+		for optionsName, opts := range mergeOptions() {
+			t.Run("should merge "+name+" "+optionsName+" like protoreflect", func(t *testing.T) {
+				// Arrange
+				previous, latest := pair(t)
+				patch, err := linearize.DiffMessages[proto.Message](previous, latest)
+				require.NoError(t, err)
+				expected := proto.Clone(previous)
+				expectedErr := linearize.MergeInto(expected, patch.Mask, patch.After, opts...)
+
+				// Act
+				actual, err := linearize.ApplyPatch(previous, patch, opts...)
+
+				// Assert
+				if expectedErr != nil {
+					assert.EqualError(t, err, expectedErr.Error())
+					return
+				}
+				require.NoError(t, err)
+				assert.True(t, proto.Equal(expected, actual), "got %v, expected %v", actual, expected)
+			})
+		}
+	}
+
+	t.Run("should leave the message unchanged when a merge fails", func(t *testing.T) {
+		// Arrange
+		msg := createSuperComplex(t)
+		mask := &linearize.UpdateMask{Values: map[int32]*linearize.UpdateMaskValue{
+			1: {Op: linearize.UpdateMaskOperation_UPDATE},
+		}}
+
+		// Act
+		_, err := linearize.ApplyPatch(msg, linearize.Patch{Mask: mask, After: linearize.LinearizedObject{1: int32(1)}})
+
+		// Assert
+		assert.Error(t, err)
+		assert.True(t, proto.Equal(createSuperComplex(t), msg))
+	})
+
+	t.Run("should reject values of the wrong type", func(t *testing.T) {
+		// Arrange
+		msg := &Scalars{}
+
+		// Act
+		err := msg.Unlinearize(linearize.LinearizedObject{3: "not an int"})
+
+		// Assert
+		assert.ErrorContains(t, err, "Int32")
+	})
+}
+
+// diffGenerated diffs two messages by their generated Diff method.
+func diffGenerated(previous, latest proto.Message, opts ...linearize.DiffOption) (linearize.Patch, error) {
+	switch previous := previous.(type) {
+	case *SuperComplex:
+		return linearize.DiffMessages(previous, latest.(*SuperComplex), opts...)
+	case *Scalars:
+		return linearize.DiffMessages(previous, latest.(*Scalars), opts...)
+	case *Annotated:
+		return linearize.DiffMessages(previous, latest.(*Annotated), opts...)
+	}
+	panic("no generated Diff method")
+}
+
+// pairs returns previous and latest versions of messages with generated methods.
+func pairs() map[string]func(t testing.TB) (previous, latest proto.Message) {
+	return map[string]func(t testing.TB) (proto.Message, proto.Message){
+		"equal messages": func(t testing.TB) (proto.Message, proto.Message) {
+			return createSuperComplex(t), createSuperComplex(t)
+		},
+		"changed fields": func(t testing.TB) (proto.Message, proto.Message) {
+			previous, latest := createSuperComplex(t), createSuperComplex(t)
+			latest.Field1 = "changed"
+			latest.Nested.Nested.Field2 = 42
+			latest.Nested.Map["key0"] = &Simple{Field1: "first"}
+			latest.Repeated[0].Repeated[0].Field2 = 7
+			latest.Map[2].Nested.Repeated = []string{"x"}
+			latest.Map[1] = &Complex{Field1: "new"}
+			latest.Map[10] = &Complex{}
+			return previous, latest
+		},
+		"grown lists": func(t testing.TB) (proto.Message, proto.Message) {
+			previous, latest := createSuperComplex(t), createSuperComplex(t)
+			latest.Repeated = append(latest.Repeated, &Complex{Field1: "appended"})
+			latest.Nested.Repeated = append(latest.Nested.Repeated, &Simple{})
+			return previous, latest
+		},
+		"shrunk lists": func(t testing.TB) (proto.Message, proto.Message) {
+			previous, latest := createSuperComplex(t), createSuperComplex(t)
+			previous.Repeated = append(previous.Repeated, &Complex{Field1: "second"})
+			latest.Repeated[0].Field1 = "changed"
+			latest.Nested.Repeated = nil
+			return previous, latest
+		},
+		"removed fields": func(t testing.TB) (proto.Message, proto.Message) {
+			previous, latest := createSuperComplex(t), createSuperComplex(t)
+			latest.Nested = nil
+			latest.Map = nil
+			latest.Field2 = 0
+			return previous, latest
+		},
+		"added fields": func(t testing.TB) (proto.Message, proto.Message) {
+			return &SuperComplex{}, createSuperComplex(t)
+		},
+		"changed scalars": func(t testing.TB) (proto.Message, proto.Message) {
+			latest := createScalars()
+			latest.Double = 0.5
+			latest.Float = 1.75
+			latest.Bytes = []byte("changed")
+			latest.OptionalInt32 = nil
+			latest.Doubles = []float64{0, 1.5, 2}
+			latest.Blobs = [][]byte{[]byte("a")}
+			latest.ByInt[3] = "three"
+			latest.ByBool[false] = &Scalars_Inner{Data: []byte("no")}
+			latest.Choice = &Scalars_Text{Text: "text"}
+			latest.External.Field2 = 7
+			return createScalars(), latest
+		},
+		"changed choice": func(t testing.TB) (proto.Message, proto.Message) {
+			latest := createScalars()
+			latest.Choice.(*Scalars_Message).Message.Data = []byte("changed")
+			return createScalars(), latest
+		},
+		"signed zeros": func(t testing.TB) (proto.Message, proto.Message) {
+			latest := createScalars()
+			latest.Double = 0
+			latest.Doubles[0] = math.Copysign(0, -1)
+			return createScalars(), latest
+		},
+		"removed scalars": func(t testing.TB) (proto.Message, proto.Message) {
+			return createScalars(), &Scalars{}
+		},
+		"annotated": func(t testing.TB) (proto.Message, proto.Message) {
+			previous := &Annotated{
+				Name:        "name",
+				UpdatedAt:   1,
+				Address:     &Simple{Field1: "street", Field2: 1},
+				Items:       []*Keyed{{Id: "a", Value: "1"}, {Id: "b", Value: "2"}},
+				Temperature: 20,
+				Secret:      "secret",
+				Password:    "password",
+			}
+			latest := &Annotated{
+				Name:        "name",
+				UpdatedAt:   2,
+				Address:     &Simple{Field1: "street", Field2: 2},
+				Items:       []*Keyed{{Id: "b", Value: "3"}, {Id: "c", Value: "4"}},
+				Temperature: 20.005,
+				Secret:      "changed",
+				Password:    "changed",
+			}
+			return previous, latest
+		},
+	}
+}
+
+// diffOptions returns the options the generated Diff methods are compared with.
+func diffOptions() map[string][]linearize.DiffOption {
+	return map[string][]linearize.DiffOption{
+		"without options":      nil,
+		"with a maximum depth": {linearize.WithMaxDepth(2)},
+		"with a large depth":   {linearize.WithMaxDepth(10)},
+		"replacing lists":      {linearize.WithListStrategy(linearize.ListReplace)},
+		"with a tolerance":     {linearize.WithFloatTolerance(linearize.FloatTolerance{Absolute: 1})},
+		"in parallel":          {linearize.WithParallelism(4)},
+		"with signed zeros":    {linearize.WithFloatTolerance(linearize.FloatTolerance{SignedZeros: true})},
+	}
+}
+
+// mergeOptions returns the options the generated Merge methods are compared with.
+func mergeOptions() map[string][]linearize.MergeOption {
+	return map[string][]linearize.MergeOption{
+		"without options":      nil,
+		"in natural key order": {linearize.WithMapKeyOrder(linearize.MapKeysNatural)},
+	}
+}
+
+func BenchmarkLinearizeReflective(b *testing.B) {
+	msg := mocks.CreateSuperComplexMessage()
+	for i := 0; i < b.N; i++ {
+		if _, err := linearize.Linearize(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLinearizeGenerated(b *testing.B) {
+	msg := createSuperComplex(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := linearize.Linearize(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnlinearizeReflective(b *testing.B) {
+	obj, err := linearize.Linearize(mocks.CreateSuperComplexMessage())
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := linearize.Unlinearize(obj, &mocks.SuperComplex{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnlinearizeGenerated(b *testing.B) {
+	obj, err := linearize.Linearize(mocks.CreateSuperComplexMessage())
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := linearize.Unlinearize(obj, &SuperComplex{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// changes returns the changes the Diff and Merge benchmarks make to a message.
+func changes() []struct {
+	name   string
+	change func(msg *SuperComplex)
+} {
+	return []struct {
+		name   string
+		change func(msg *SuperComplex)
+	}{
+		{"unchanged", func(msg *SuperComplex) {}},
+		{"one field", func(msg *SuperComplex) { msg.Field1 = "changed" }},
+		{"nested fields", func(msg *SuperComplex) {
+			msg.Field1 = "changed"
+			msg.Nested.Map["key1"].Field2 = 7
+			msg.Repeated[0].Repeated[0].Field1 = "changed"
+		}},
+	}
+}
+
+// benchmarkDiff runs diff on a message and a changed copy of it for each change.
+func benchmarkDiff(b *testing.B, diff func(previous, latest *SuperComplex) (linearize.Patch, error)) {
+	for _, c := range changes() {
+		b.Run(c.name, func(b *testing.B) {
+			previous, latest := createSuperComplex(b), createSuperComplex(b)
+			c.change(latest)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := diff(previous, latest); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkMerge runs merge with the patch of each change.
+func benchmarkMerge(b *testing.B, merge func(msg *SuperComplex, patch linearize.Patch) error) {
+	for _, c := range changes() {
+		b.Run(c.name, func(b *testing.B) {
+			previous, latest := createSuperComplex(b), createSuperComplex(b)
+			c.change(latest)
+			patch, err := linearize.DiffMessages(previous, latest)
+			require.NoError(b, err)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := merge(previous, patch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDiffReflective(b *testing.B) {
+	benchmarkDiff(b, func(previous, latest *SuperComplex) (linearize.Patch, error) {
+		return linearize.DiffMessages[proto.Message](previous, latest)
+	})
+}
+
+func BenchmarkDiffGenerated(b *testing.B) {
+	benchmarkDiff(b, func(previous, latest *SuperComplex) (linearize.Patch, error) {
+		return linearize.DiffMessages(previous, latest)
+	})
+}
+
+func BenchmarkMergeReflective(b *testing.B) {
+	benchmarkMerge(b, func(msg *SuperComplex, patch linearize.Patch) error {
+		return linearize.MergeInto(msg, patch.Mask, patch.After)
+	})
+}
+
+func BenchmarkMergeGenerated(b *testing.B) {
+	benchmarkMerge(b, func(msg *SuperComplex, patch linearize.Patch) error {
+		return msg.Merge(patch.Mask, patch.After)
+	})
+}
@@ -0,0 +1,3 @@
+// Package sqltest runs the statements of SQLTable against an in-memory SQLite database. It is a
+// separate module so the root module does not depend on the SQLite driver.
+package sqltest
@@ -0,0 +1,130 @@
+package sqltest
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/fgrzl/linearize"
+	"github.com/fgrzl/linearize/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	_ "modernc.org/sqlite"
+)
+
+// openProjection opens an in-memory SQLite database holding an empty path/value table.
+func openProjection(t *testing.T, table linearize.SQLTable) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(table.CreateStatement())
+	require.NoError(t, err)
+	return db
+}
+
+// execStatements runs statements in a transaction.
+func execStatements(t *testing.T, db *sql.DB, statements []linearize.Statement) {
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	for _, statement := range statements {
+		_, err := tx.Exec(statement.SQL, statement.Args...)
+		require.NoError(t, err, statement.SQL)
+	}
+	require.NoError(t, tx.Commit())
+}
+
+// loadProjection reads the rows of a document back into an object.
+func loadProjection(t *testing.T, db *sql.DB, table linearize.SQLTable, key string, msg proto.Message) linearize.LinearizedObject {
+	rows, err := db.Query(`SELECT "path", "type", "value" FROM "`+table.Name+`" WHERE "doc" = ?`, key)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var flattened []linearize.Row
+	for rows.Next() {
+		var path, valueType string
+		var value sql.NullString
+		require.NoError(t, rows.Scan(&path, &valueType, &value))
+		row, err := linearize.ParseSQLRow(msg.ProtoReflect().Descriptor(), path, valueType, value)
+		require.NoError(t, err)
+		flattened = append(flattened, row)
+	}
+	require.NoError(t, rows.Err())
+
+	obj, err := linearize.Unflatten(flattened, linearize.MapKeysLexical)
+	require.NoError(t, err)
+	return obj
+}
+
+func TestSQLiteProjection(t *testing.T) {
+	changes := map[string]func(msg *mocks.SuperComplex){
+		"no change":       func(msg *mocks.SuperComplex) {},
+		"scalar":          func(msg *mocks.SuperComplex) { msg.Field1 = "changed" },
+		"nested scalar":   func(msg *mocks.SuperComplex) { msg.Nested.Nested.Field2 = 7 },
+		"removed field":   func(msg *mocks.SuperComplex) { msg.Field2 = 0 },
+		"cleared message": func(msg *mocks.SuperComplex) { msg.Nested.Nested = &mocks.Simple{} },
+		"filled message":  func(msg *mocks.SuperComplex) { msg.Repeated[2].Nested = mocks.CreateSimpleMessage() },
+		"removed message": func(msg *mocks.SuperComplex) { msg.Nested = nil },
+		"appended element": func(msg *mocks.SuperComplex) {
+			msg.Repeated = append(msg.Repeated, &mocks.Complex{Field1: "appended"})
+		},
+		"removed elements": func(msg *mocks.SuperComplex) { msg.Repeated = msg.Repeated[:1] },
+		"changed element":  func(msg *mocks.SuperComplex) { msg.Repeated[0].Nested.Field1 = "changed" },
+		"removed scalar element": func(msg *mocks.SuperComplex) {
+			msg.Nested.Nested.Repeated = msg.Nested.Nested.Repeated[:1]
+		},
+		"changed map entry": func(msg *mocks.SuperComplex) { msg.Map[2].Field2 = 1 },
+		"added map entry": func(msg *mocks.SuperComplex) {
+			msg.Nested.Map["key0"] = &mocks.Simple{Field1: "first"}
+		},
+		"removed map entry": func(msg *mocks.SuperComplex) { delete(msg.Nested.Map, "key1") },
+		"shifted map entries": func(msg *mocks.SuperComplex) {
+			msg.Nested.Map["key10"] = &mocks.Simple{Field1: "between"}
+			msg.Map[10] = &mocks.Complex{Field1: "before 2"}
+		},
+		"renamed map entry": func(msg *mocks.SuperComplex) {
+			msg.Nested.Map["key3"] = msg.Nested.Map["key1"]
+			delete(msg.Nested.Map, "key1")
+		},
+	}
+
+	for name, change := range changes {
+		t.Run("should project "+name+" into sqlite", func(t *testing.T) {
+			// Arrange
+			table := linearize.NewSQLTable(linearize.SQLite, "documents")
+			db := openProjection(t, table)
+			previous := mocks.CreateSuperComplexMessage()
+			previous.Repeated = append(previous.Repeated, mocks.CreateComplexMessage(), &mocks.Complex{Nested: &mocks.Simple{}})
+			latest := proto.Clone(previous).(*mocks.SuperComplex)
+			change(latest)
+
+			previousObj, err := linearize.Linearize(previous)
+			require.NoError(t, err)
+			statements, err := table.InsertStatements("doc", previousObj)
+			require.NoError(t, err)
+			execStatements(t, db, statements)
+			other, err := table.InsertStatements("other", previousObj)
+			require.NoError(t, err)
+			execStatements(t, db, other)
+			patch, err := linearize.DiffMessages(previous, latest)
+			require.NoError(t, err)
+
+			// Act
+			statements, err = table.PatchStatements("doc", patch)
+			require.NoError(t, err)
+			execStatements(t, db, statements)
+
+			// Assert
+			expected, err := linearize.Linearize(latest)
+			require.NoError(t, err)
+			assert.Equal(t, expected, loadProjection(t, db, table, "doc", latest))
+			expectedRows, err := linearize.Flatten(expected)
+			require.NoError(t, err)
+			var count int
+			require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM "documents" WHERE "doc" = ?`, "doc").Scan(&count))
+			assert.Len(t, expectedRows, count)
+			assert.Equal(t, previousObj, loadProjection(t, db, table, "other", previous))
+		})
+	}
+}